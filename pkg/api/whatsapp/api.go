@@ -3,11 +3,15 @@ package whatsapp
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	businessProfileAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/business_profile"
 	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
 	mediaAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/media"
 	messageAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/message"
+	phoneNumberAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/phone_number"
+	templateAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/template"
+	uploadAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/upload"
 	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
 	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
 	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
@@ -15,13 +19,27 @@ import (
 )
 
 type WhatsAppClient struct {
-	ctx    context.Context
-	logger *zap.Logger
-	client *customClient.Client
-	cfg    types.WhatsAppConfig
+	ctx             context.Context
+	logger          *zap.Logger
+	client          *customClient.Client
+	cfg             types.WhatsAppConfig
+	mediaCache      mediaAPI.Store
+	messageGuard    messageAPI.Guard
+	messageRecorder messageAPI.Recorder
+}
+
+// resources shared between the clients, created on demand if not available
+type sharedResources struct {
+	httpClient *http.Client
+	limiter    *customClient.Limiter
+	mediaCache mediaAPI.Store
 }
 
 func New(ctx context.Context, cfg types.WhatsAppConfig) (*WhatsAppClient, error) {
+	return newClient(ctx, cfg, &sharedResources{})
+}
+
+func newClient(ctx context.Context, cfg types.WhatsAppConfig, shared *sharedResources) (*WhatsAppClient, error) {
 	logger, err := loggerUtils.FromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -47,25 +65,118 @@ func New(ctx context.Context, cfg types.WhatsAppConfig) (*WhatsAppClient, error)
 		logger.Error("error on getting custom http client", zap.Error(err))
 		return nil, err
 	}
+	if shared.httpClient != nil {
+		client.SetHTTPClient(shared.httpClient)
+	}
+	client.SetRetryPolicy(customClient.NewRetryPolicy(cfg.Retry))
+	if cfg.RateLimit.Enabled && shared.limiter == nil {
+		shared.limiter = customClient.NewLimiter(cfg.RateLimit)
+	}
+	if shared.limiter != nil {
+		client.SetLimiter(shared.limiter)
+	}
+
+	if cfg.MediaCache.Enabled && shared.mediaCache == nil {
+		if cfg.MediaCache.Path != "" {
+			fileStore, err := mediaAPI.NewFileStore(cfg.MediaCache.Path, cfg.MediaCache.MaxEntries)
+			if err != nil {
+				logger.Error("error on loading media cache", zap.String("path", cfg.MediaCache.Path), zap.Error(err))
+				return nil, err
+			}
+			shared.mediaCache = fileStore
+		} else {
+			shared.mediaCache = mediaAPI.NewMemoryStore(cfg.MediaCache.MaxEntries)
+		}
+	}
 
 	whatsAppClient := &WhatsAppClient{
-		ctx:    ctx,
-		logger: logger,
-		cfg:    cfg,
-		client: client,
+		ctx:        ctx,
+		logger:     logger,
+		cfg:        cfg,
+		client:     client,
+		mediaCache: shared.mediaCache,
 	}
 
 	return whatsAppClient, nil
 }
 
+// Config returns the configuration of the client
+func (wc *WhatsAppClient) Config() types.WhatsAppConfig {
+	return wc.cfg
+}
+
+// OnAttempt registers a hook, called after each api request attempt
+func (wc *WhatsAppClient) OnAttempt(hook customClient.AttemptHook) {
+	wc.client.AddAttemptHook(hook)
+}
+
 func (wc *WhatsAppClient) BusinessProfile() *businessProfileAPI.BusinessProfileAPI {
-	return businessProfileAPI.New(wc.ctx, wc.client, wc.cfg.PhoneNumberID)
+	return businessProfileAPI.New(wc.ctx, wc.client, wc.cfg.PhoneNumberID, wc.cfg.AppID)
 }
 
 func (wc *WhatsAppClient) Media() *mediaAPI.MediaAPI {
-	return mediaAPI.New(wc.ctx, wc.client, wc.cfg.PhoneNumberID)
+	api := mediaAPI.New(wc.ctx, wc.client, wc.cfg.PhoneNumberID)
+	api.SetValidation(!wc.cfg.SkipValidation)
+	if wc.mediaCache != nil {
+		api.SetCache(wc.mediaCache, wc.cfg.MediaCache.TTL)
+	}
+	return api
 }
 
 func (wc *WhatsAppClient) Message() *messageAPI.MessageAPI {
-	return messageAPI.New(wc.ctx, wc.client, wc.cfg.PhoneNumberID)
+	api := messageAPI.New(wc.ctx, wc.client, wc.cfg.PhoneNumberID)
+	api.SetValidation(!wc.cfg.SkipValidation)
+	api.SetGuard(wc.messageGuard)
+	api.SetRecorder(wc.messageRecorder)
+	return api
+}
+
+// SendMedia uploads the media and posts the message with the media id, the media object of the message type should be set.
+// if the media is not found (expired or deleted), uploads again and posts the message once again
+func (wc *WhatsAppClient) SendMedia(ctx context.Context, message whatsappTY.Message, media *whatsappTY.Media) (*whatsappTY.MessageResponse, error) {
+	mediaObject := message.MediaObject()
+	if mediaObject == nil {
+		return nil, fmt.Errorf("message type[%s] has no media object", message.Type)
+	}
+	api := wc.Message()
+	var out *whatsappTY.MessageResponse
+	err := wc.Media().WithReupload(ctx, media, func(ctx context.Context, mediaID string) error {
+		// copy the media object, the caller message is not modified
+		withID := *mediaObject
+		withID.ID = mediaID
+		withID.Link = ""
+		message.SetMediaObject(&withID)
+		response, err := api.Post(ctx, message)
+		if err != nil {
+			return err
+		}
+		out = response
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetMessageGuard sets the guard of the message api, example: customer service window guard
+func (wc *WhatsAppClient) SetMessageGuard(guard messageAPI.Guard) {
+	wc.messageGuard = guard
+}
+
+// SetMessageRecorder sets the recorder of the message api, example: delivery status ledger
+func (wc *WhatsAppClient) SetMessageRecorder(recorder messageAPI.Recorder) {
+	wc.messageRecorder = recorder
+}
+
+func (wc *WhatsAppClient) PhoneNumbers() *phoneNumberAPI.PhoneNumberAPI {
+	return phoneNumberAPI.New(wc.ctx, wc.client, wc.cfg.BusinessAccountID)
+}
+
+func (wc *WhatsAppClient) Templates() *templateAPI.TemplateAPI {
+	return templateAPI.New(wc.ctx, wc.client, wc.cfg.BusinessAccountID)
+}
+
+func (wc *WhatsAppClient) Upload() *uploadAPI.UploadAPI {
+	return uploadAPI.New(wc.ctx, wc.client, wc.cfg.AppID)
 }
@@ -3,31 +3,43 @@ package businessprofile
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	uploadAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/upload"
 	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
 )
 
+// DefaultFields returned by Get, when the fields are not specified
+var DefaultFields = []string{"about", "address", "description", "email", "profile_picture_url", "websites", "vertical"}
+
 type BusinessProfileAPI struct {
 	phoneNumberID string
 	client        *customClient.Client
+	uploader      *uploadAPI.UploadAPI
 }
 
-func New(ctx context.Context, client *customClient.Client, phoneNumberID string) *BusinessProfileAPI {
+func New(ctx context.Context, client *customClient.Client, phoneNumberID, appID string) *BusinessProfileAPI {
 	return &BusinessProfileAPI{
 		phoneNumberID: phoneNumberID,
 		client:        client,
+		uploader:      uploadAPI.New(ctx, client, appID),
 	}
 }
 
-func (bp *BusinessProfileAPI) Get() (*whatsappTY.BusinessProfile, error) {
+// Get returns the business profile with the given fields, DefaultFields used if not specified
+func (bp *BusinessProfileAPI) Get(ctx context.Context, fields ...string) (*whatsappTY.BusinessProfile, error) {
 	// /{{Phone-Number-ID}}/whatsapp_business_profile
 	api := fmt.Sprintf("/%s/whatsapp_business_profile", bp.phoneNumberID)
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+	queryParams := map[string]string{"fields": strings.Join(fields, ",")}
 	// {"data":[{"messaging_product":"whatsapp"}]}
 	out := struct {
 		Data []whatsappTY.BusinessProfile `json:"data"`
 	}{}
-	err := bp.client.Get(api, nil, nil, &out)
+	err := bp.client.Get(ctx, api, nil, queryParams, &out)
 	if err != nil {
 		return nil, err
 	}
@@ -36,3 +48,38 @@ func (bp *BusinessProfileAPI) Get() (*whatsappTY.BusinessProfile, error) {
 	}
 	return &whatsappTY.BusinessProfile{}, nil
 }
+
+// Update updates the non empty fields of the business profile
+func (bp *BusinessProfileAPI) Update(ctx context.Context, profile *whatsappTY.BusinessProfile) error {
+	if profile == nil {
+		return fmt.Errorf("business profile can not be nil")
+	}
+	err := profile.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid business profile: %w", err)
+	}
+
+	// /{{Phone-Number-ID}}/whatsapp_business_profile
+	api := fmt.Sprintf("/%s/whatsapp_business_profile", bp.phoneNumberID)
+	body := *profile
+	body.MessagingProduct = whatsappTY.DEFAULT_MESSAGING_PRODUCT
+	body.ProfilePictureURL = "" // read only
+	out := &whatsappTY.StatusResponse{}
+	err = bp.client.Post(ctx, api, nil, nil, &body, out)
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return fmt.Errorf("error on updating business profile:%s", bp.phoneNumberID)
+	}
+	return nil
+}
+
+// SetProfilePicture uploads the image with the resumable upload api and updates the profile picture
+func (bp *BusinessProfileAPI) SetProfilePicture(ctx context.Context, imagePath string) error {
+	handle, err := bp.uploader.UploadFile(ctx, imagePath, nil)
+	if err != nil {
+		return fmt.Errorf("error on uploading profile picture: %w", err)
+	}
+	return bp.Update(ctx, &whatsappTY.BusinessProfile{ProfilePictureHandle: handle})
+}
@@ -0,0 +1,259 @@
+package businessprofile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// received request of the test server
+type testRequest struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// serves the business profile and the resumable upload apis, records the requests
+type testServer struct {
+	mutex    sync.Mutex
+	requests []testRequest
+	profile  string // response of get
+	success  bool   // response of update
+}
+
+func (ts *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	ts.requests = append(ts.requests, testRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query(), header: r.Header, body: body})
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/phone-1/whatsapp_business_profile":
+		fmt.Fprint(w, ts.profile)
+	case r.Method == http.MethodPost && r.URL.Path == "/phone-1/whatsapp_business_profile":
+		fmt.Fprintf(w, `{"success":%t}`, ts.success)
+	case r.Method == http.MethodPost && r.URL.Path == "/app-1/uploads":
+		fmt.Fprint(w, `{"id":"session-1"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/session-1":
+		fmt.Fprint(w, `{"h":"handle-1"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"message":"not found","code":100}}`)
+	}
+}
+
+func newTestBusinessProfileAPI(t *testing.T, server *testServer) *BusinessProfileAPI {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+	client, err := customClient.New(ctx, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(ctx, client, "phone-1", "app-1")
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     []string
+		profile    string
+		wantFields string
+		want       *whatsappTY.BusinessProfile
+	}{
+		{
+			name:       "default fields",
+			profile:    `{"data":[{"about":"hello","vertical":"RETAIL","websites":["https://example.com"],"messaging_product":"whatsapp"}]}`,
+			wantFields: strings.Join(DefaultFields, ","),
+			want:       &whatsappTY.BusinessProfile{About: "hello", Vertical: "RETAIL", Websites: []string{"https://example.com"}, MessagingProduct: "whatsapp"},
+		},
+		{
+			name:       "selected fields",
+			fields:     []string{"about", "email"},
+			profile:    `{"data":[{"about":"hello","email":"info@example.com"}]}`,
+			wantFields: "about,email",
+			want:       &whatsappTY.BusinessProfile{About: "hello", Email: "info@example.com"},
+		},
+		{
+			name:       "empty data",
+			profile:    `{"data":[]}`,
+			wantFields: strings.Join(DefaultFields, ","),
+			want:       &whatsappTY.BusinessProfile{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &testServer{profile: tc.profile}
+			api := newTestBusinessProfileAPI(t, server)
+
+			got, err := api.Get(context.TODO(), tc.fields...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("profile = %+v, want %+v", got, tc.want)
+			}
+			if len(server.requests) != 1 {
+				t.Fatalf("requests = %d, want 1", len(server.requests))
+			}
+			if fields := server.requests[0].query.Get("fields"); fields != tc.wantFields {
+				t.Errorf("fields = %q, want %q", fields, tc.wantFields)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  *whatsappTY.BusinessProfile
+		success  bool
+		wantBody map[string]any // nil, request not sent
+		wantErr  string
+	}{
+		{
+			name:     "update",
+			profile:  &whatsappTY.BusinessProfile{About: "hello", Websites: []string{"https://example.com"}},
+			success:  true,
+			wantBody: map[string]any{"about": "hello", "websites": []any{"https://example.com"}, "messaging_product": "whatsapp"},
+		},
+		{
+			name:     "read only picture url not sent",
+			profile:  &whatsappTY.BusinessProfile{About: "hello", ProfilePictureURL: "https://example.com/logo.png"},
+			success:  true,
+			wantBody: map[string]any{"about": "hello", "messaging_product": "whatsapp"},
+		},
+		{
+			name:    "invalid email",
+			profile: &whatsappTY.BusinessProfile{Email: "example.com"},
+			wantErr: "invalid business profile",
+		},
+		{
+			name:    "invalid website and vertical",
+			profile: &whatsappTY.BusinessProfile{Websites: []string{"example.com"}, Vertical: "SPACE"},
+			wantErr: "must start with http:// or https://",
+		},
+		{
+			name:    "nil profile",
+			wantErr: "business profile can not be nil",
+		},
+		{
+			name:     "not succeeded",
+			profile:  &whatsappTY.BusinessProfile{About: "hello"},
+			wantBody: map[string]any{"about": "hello", "messaging_product": "whatsapp"},
+			wantErr:  "error on updating business profile",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &testServer{success: tc.success}
+			api := newTestBusinessProfileAPI(t, server)
+
+			err := api.Update(context.TODO(), tc.profile)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tc.wantErr)
+			}
+
+			if tc.wantBody == nil {
+				if len(server.requests) != 0 {
+					t.Errorf("requests = %d, want 0", len(server.requests))
+				}
+				return
+			}
+			if len(server.requests) != 1 {
+				t.Fatalf("requests = %d, want 1", len(server.requests))
+			}
+			body := map[string]any{}
+			if err := json.Unmarshal(server.requests[0].body, &body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body, tc.wantBody) {
+				t.Errorf("body = %v, want %v", body, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestSetProfilePicture(t *testing.T) {
+	content := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 16)
+	imagePath := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(imagePath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := &testServer{success: true}
+	api := newTestBusinessProfileAPI(t, server)
+
+	if err := api.SetProfilePicture(context.TODO(), imagePath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(server.requests))
+	}
+
+	// upload session
+	session := server.requests[0]
+	if session.method != http.MethodPost || session.path != "/app-1/uploads" {
+		t.Errorf("session request = %s %s", session.method, session.path)
+	}
+	wantQuery := map[string]string{"file_name": "logo.png", "file_length": fmt.Sprint(len(content)), "file_type": "image/png"}
+	for key, want := range wantQuery {
+		if got := session.query.Get(key); got != want {
+			t.Errorf("session %s = %q, want %q", key, got, want)
+		}
+	}
+
+	// file data
+	chunk := server.requests[1]
+	if chunk.method != http.MethodPost || chunk.path != "/session-1" {
+		t.Errorf("chunk request = %s %s", chunk.method, chunk.path)
+	}
+	if offset := chunk.header.Get("file_offset"); offset != "0" {
+		t.Errorf("file offset = %q, want 0", offset)
+	}
+	if !bytes.Equal(chunk.body, content) {
+		t.Errorf("chunk body = %v, want %v", chunk.body, content)
+	}
+
+	// profile update with the handle
+	update := server.requests[2]
+	if update.method != http.MethodPost || update.path != "/phone-1/whatsapp_business_profile" {
+		t.Errorf("update request = %s %s", update.method, update.path)
+	}
+	body := map[string]any{}
+	if err := json.Unmarshal(update.body, &body); err != nil {
+		t.Fatal(err)
+	}
+	wantBody := map[string]any{"profile_picture_handle": "handle-1", "messaging_product": "whatsapp"}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("update body = %v, want %v", body, wantBody)
+	}
+
+	if err := api.SetProfilePicture(context.TODO(), filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected error on missing file")
+	}
+}
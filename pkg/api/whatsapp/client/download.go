@@ -0,0 +1,97 @@
+package whatsapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Download streams the content of the absolute url into the writer, returns the number of bytes written.
+// the request carries the client headers (bearer token), required for the media urls (lookaside.fbsbx.com).
+// retried based on the retry policy, until the first byte written into the writer
+func (c *Client) Download(ctx context.Context, url string, headers map[string]string, w io.Writer) (int64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := c.getLogger(ctx)
+
+	logger.Debug("received download request", zap.String("url", url))
+
+	policy := c.retryPolicy
+	maxAttempts := policy.attempts()
+
+	for attempt := 1; ; attempt++ {
+		startTime := time.Now()
+		written, statusCode, err := c.executeDownload(ctx, logger, url, headers, w)
+
+		info := AttemptInfo{
+			Method:      http.MethodGet,
+			URL:         url,
+			Attempt:     attempt,
+			MaxAttempts: maxAttempts,
+			StatusCode:  statusCode,
+			Duration:    time.Since(startTime),
+			Err:         err,
+		}
+		// partially written content can not be reverted
+		if err != nil && written == 0 && attempt < maxAttempts && ctx.Err() == nil && policy.shouldRetry(http.MethodGet, err) {
+			info.WillRetry = true
+			info.NextDelay = policy.delay(attempt, err)
+		}
+		c.runAttemptHooks(ctx, info)
+
+		if !info.WillRetry {
+			return written, err
+		}
+
+		logger.Debug("retrying the download", zap.String("url", url), zap.Int("attempt", attempt), zap.Duration("delay", info.NextDelay), zap.Error(err))
+		timer := time.NewTimer(info.NextDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return written, err
+		case <-timer.C:
+		}
+	}
+}
+
+// executes a single attempt of the download
+func (c *Client) executeDownload(ctx context.Context, logger *zap.Logger, url string, headers map[string]string, w io.Writer) (int64, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		logger.Error("error on getting a new request", zap.Error(err))
+		return 0, 0, err
+	}
+	c.setHeaders(req, headers)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		logger.Error("error on executing a download request", zap.Error(err))
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	logger.Debug("response received", zap.String("url", url), zap.String("status", resp.Status))
+
+	if resp.StatusCode != http.StatusOK {
+		respBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			logger.Error("error on reading a response body", zap.Error(err))
+			return 0, resp.StatusCode, err
+		}
+		graphErr := newGraphError(resp.StatusCode, resp.Status, respBytes)
+		graphErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		logger.Error("download failed", zap.Int("statusCode", resp.StatusCode), zap.Int("code", graphErr.Code), zap.String("fbtraceId", graphErr.FBTraceID))
+		return 0, resp.StatusCode, graphErr
+	}
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		logger.Error("error on copying the response body", zap.Int64("written", written), zap.Error(err))
+		return written, resp.StatusCode, err
+	}
+	return written, resp.StatusCode, nil
+}
@@ -0,0 +1,311 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// cloud api error codes
+// see: https://developers.facebook.com/docs/whatsapp/cloud-api/support/error-codes
+const (
+	// authorization errors
+	ErrorCodeAuthException      = 0
+	ErrorCodeAPIMethod          = 3
+	ErrorCodePermissionDenied   = 10
+	ErrorCodeAccessTokenExpired = 190
+	ErrorCodeAPIPermissionStart = 200 // 200-299, api permission
+	ErrorCodeAPIPermissionEnd   = 299
+
+	// throttling errors
+	ErrorCodeAPITooManyCalls  = 4
+	ErrorCodeRateLimitIssues  = 80007
+	ErrorCodeRateLimitHit     = 130429
+	ErrorCodeSpamRateLimitHit = 131048
+	ErrorCodePairRateLimitHit = 131056
+
+	// integrity errors
+	ErrorCodeTemporarilyBlocked = 368
+	ErrorCodeAccountLocked      = 131031
+
+	// other errors
+	ErrorCodeAPIUnknown                   = 1
+	ErrorCodeAPIService                   = 2
+	ErrorCodeInvalidParameterValue        = 33
+	ErrorCodeInvalidParameter             = 100
+	ErrorCodeNumberInExperiment           = 130472
+	ErrorCodeSomethingWentWrong           = 131000
+	ErrorCodeAccessDenied                 = 131005
+	ErrorCodeRequiredParameterMissing     = 131008
+	ErrorCodeParameterValueInvalid        = 131009
+	ErrorCodeServiceUnavailable           = 131016
+	ErrorCodeRecipientCannotBeSender      = 131021
+	ErrorCodeMessageUndeliverable         = 131026
+	ErrorCodeBusinessPaymentIssue         = 131042
+	ErrorCodeIncorrectCertificate         = 131045
+	ErrorCodeReEngagementMessage          = 131047
+	ErrorCodeMarketingMessageNotDelivered = 131049
+	ErrorCodeUserStoppedMarketing         = 131050
+	ErrorCodeUnsupportedMessageType       = 131051
+	ErrorCodeMediaDownloadError           = 131052
+	ErrorCodeMediaUploadError             = 131053
+	ErrorCodeAccountInMaintenanceMode     = 131057
+	ErrorCodeTemplateParamCountMismatch   = 132000
+	ErrorCodeTemplateDoesNotExist         = 132001
+	ErrorCodeTemplateTextTooLong          = 132005
+	ErrorCodeTemplateFormatPolicy         = 132007
+	ErrorCodeTemplateParamFormatMismatch  = 132012
+	ErrorCodeTemplatePaused               = 132015
+	ErrorCodeTemplateDisabled             = 132016
+	ErrorCodeFlowBlocked                  = 132068
+	ErrorCodeFlowThrottled                = 132069
+	ErrorCodeIncompleteDeregistration     = 133000
+	ErrorCodeServerTemporarilyUnavailable = 133004
+	ErrorCodeTwoStepPINMismatch           = 133005
+	ErrorCodeNumberReVerificationNeeded   = 133006
+	ErrorCodeTooManyPINGuesses            = 133008
+	ErrorCodePINGuessedTooFast            = 133009
+	ErrorCodeNumberNotRegistered          = 133010
+	ErrorCodeRegisterWaitFewMinutes       = 133015
+	ErrorCodeGenericUserError             = 135000
+)
+
+// graph api error subcodes
+const (
+	// object with the id does not exist, or can not be loaded due to missing permissions
+	ErrorSubcodeObjectNotFound = 33
+)
+
+var errorCodeDescriptions = map[int]string{
+	ErrorCodeAuthException:                "unable to authenticate the app user",
+	ErrorCodeAPIMethod:                    "capability or permissions issue",
+	ErrorCodePermissionDenied:             "permission is either not granted or has been removed",
+	ErrorCodeAccessTokenExpired:           "access token has expired",
+	ErrorCodeAPITooManyCalls:              "app has reached its api call rate limit",
+	ErrorCodeRateLimitIssues:              "whatsapp business account has reached its rate limit",
+	ErrorCodeRateLimitHit:                 "cloud api message throughput has been reached",
+	ErrorCodeSpamRateLimitHit:             "message failed to send because there are restrictions on how many messages can be sent from this phone number",
+	ErrorCodePairRateLimitHit:             "too many messages sent from the sender phone number to the same recipient phone number in a short period of time",
+	ErrorCodeTemporarilyBlocked:           "temporarily blocked for policies violations",
+	ErrorCodeAccountLocked:                "whatsapp business account is restricted or disabled for violating a platform policy",
+	ErrorCodeAPIUnknown:                   "invalid request or possible server error",
+	ErrorCodeAPIService:                   "temporary due to downtime or due to being overloaded",
+	ErrorCodeInvalidParameterValue:        "business phone number has been deleted",
+	ErrorCodeInvalidParameter:             "request included one or more unsupported or misspelled parameters",
+	ErrorCodeNumberInExperiment:           "recipient's phone number is part of an experiment",
+	ErrorCodeSomethingWentWrong:           "message failed to send due to an unknown error",
+	ErrorCodeAccessDenied:                 "permission is either not granted or has been removed",
+	ErrorCodeRequiredParameterMissing:     "request is missing a required parameter",
+	ErrorCodeParameterValueInvalid:        "one or more parameter values are invalid",
+	ErrorCodeServiceUnavailable:           "service is temporarily unavailable",
+	ErrorCodeRecipientCannotBeSender:      "sender and recipient phone number is the same",
+	ErrorCodeMessageUndeliverable:         "message undeliverable",
+	ErrorCodeBusinessPaymentIssue:         "there was an error related to the payment method",
+	ErrorCodeIncorrectCertificate:         "message failed to send due to a phone number registration error",
+	ErrorCodeReEngagementMessage:          "more than 24 hours have passed since the recipient last replied to the sender number",
+	ErrorCodeMarketingMessageNotDelivered: "message was not delivered to maintain healthy ecosystem engagement",
+	ErrorCodeUserStoppedMarketing:         "user has stopped marketing messages",
+	ErrorCodeUnsupportedMessageType:       "unsupported message type",
+	ErrorCodeMediaDownloadError:           "unable to download the media sent by the user",
+	ErrorCodeMediaUploadError:             "unable to upload the media used in the message",
+	ErrorCodeAccountInMaintenanceMode:     "business account is in maintenance mode",
+	ErrorCodeTemplateParamCountMismatch:   "number of variable parameter values does not match the number of variable parameters defined in the template",
+	ErrorCodeTemplateDoesNotExist:         "template does not exist in the specified language or the template has not been approved",
+	ErrorCodeTemplateTextTooLong:          "translated text is too long",
+	ErrorCodeTemplateFormatPolicy:         "template content violates a whatsapp policy",
+	ErrorCodeTemplateParamFormatMismatch:  "variable parameter values formatted incorrectly",
+	ErrorCodeTemplatePaused:               "template is paused due to low quality",
+	ErrorCodeTemplateDisabled:             "template has been paused too many times due to low quality and is now permanently disabled",
+	ErrorCodeFlowBlocked:                  "flow is in blocked state",
+	ErrorCodeFlowThrottled:                "flow is in throttled state",
+	ErrorCodeIncompleteDeregistration:     "a previous deregistration attempt failed",
+	ErrorCodeServerTemporarilyUnavailable: "server is temporarily unavailable",
+	ErrorCodeTwoStepPINMismatch:           "two-step verification pin incorrect",
+	ErrorCodeNumberReVerificationNeeded:   "phone number needs to be verified before registering",
+	ErrorCodeTooManyPINGuesses:            "too many two-step verification pin guesses for this phone number",
+	ErrorCodePINGuessedTooFast:            "two-step verification pin was entered too quickly",
+	ErrorCodeNumberNotRegistered:          "phone number is not registered on the whatsapp business platform",
+	ErrorCodeRegisterWaitFewMinutes:       "phone number was recently deleted, and deletion has not yet completed",
+	ErrorCodeGenericUserError:             "unknown error with request parameters",
+}
+
+// ErrorCodeDescription returns the documented description of the given error code
+func ErrorCodeDescription(code int) string {
+	if description, found := errorCodeDescriptions[code]; found {
+		return description
+	}
+	if code >= ErrorCodeAPIPermissionStart && code <= ErrorCodeAPIPermissionEnd {
+		return "permission is either not granted or has been removed"
+	}
+	return ""
+}
+
+// GraphError is the error returned by the graph api
+// https://developers.facebook.com/docs/graph-api/guides/error-handling
+type GraphError struct {
+	Message      string         `json:"message,omitempty"`
+	Type         string         `json:"type,omitempty"`
+	Code         int            `json:"code,omitempty"`
+	ErrorSubcode int            `json:"error_subcode,omitempty"`
+	ErrorData    GraphErrorData `json:"error_data,omitempty"`
+	UserTitle    string         `json:"error_user_title,omitempty"`
+	UserMessage  string         `json:"error_user_msg,omitempty"`
+	FBTraceID    string         `json:"fbtrace_id,omitempty"`
+	HTTPStatus   int            `json:"-"`
+	RetryAfter   time.Duration  `json:"-"` // value of the "Retry-After" response header, if any
+	RawBody      string         `json:"-"` // used when the response is not a graph error envelope
+}
+
+type GraphErrorData struct {
+	MessagingProduct string `json:"messaging_product,omitempty"`
+	Details          string `json:"details,omitempty"`
+}
+
+func (ge *GraphError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("graph api error [status: %d, code: %d", ge.HTTPStatus, ge.Code))
+	if ge.ErrorSubcode != 0 {
+		sb.WriteString(fmt.Sprintf(", subcode: %d", ge.ErrorSubcode))
+	}
+	if ge.Type != "" {
+		sb.WriteString(fmt.Sprintf(", type: %s", ge.Type))
+	}
+	if ge.FBTraceID != "" {
+		sb.WriteString(fmt.Sprintf(", fbtrace_id: %s", ge.FBTraceID))
+	}
+	sb.WriteString("]")
+	if ge.Message != "" {
+		sb.WriteString(": ")
+		sb.WriteString(ge.Message)
+	}
+	if ge.ErrorData.Details != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", ge.ErrorData.Details))
+	}
+	return sb.String()
+}
+
+// Description returns the documented description of the error code
+func (ge *GraphError) Description() string {
+	return ErrorCodeDescription(ge.Code)
+}
+
+// parses the graph api error envelope from the response body
+// if the body is not a valid envelope, returns a GraphError with the raw body
+func newGraphError(statusCode int, status string, body []byte) *GraphError {
+	envelope := struct {
+		Error *GraphError `json:"error"`
+	}{}
+	err := json.Unmarshal(body, &envelope)
+	if err != nil || envelope.Error == nil {
+		return &GraphError{
+			Message:    status,
+			HTTPStatus: statusCode,
+			RawBody:    string(body),
+		}
+	}
+	envelope.Error.HTTPStatus = statusCode
+	return envelope.Error
+}
+
+// AsGraphError returns the GraphError, if the err chain has it
+func AsGraphError(err error) (*GraphError, bool) {
+	var graphErr *GraphError
+	if errors.As(err, &graphErr) {
+		return graphErr, true
+	}
+	return nil, false
+}
+
+// HasErrorCode reports whether the err is a GraphError with any of the given codes
+func HasErrorCode(err error, codes ...int) bool {
+	graphErr, ok := AsGraphError(err)
+	if !ok {
+		return false
+	}
+	for _, code := range codes {
+		if graphErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRateLimited reports whether the request was throttled by the api or by the pair/spam limits
+func IsRateLimited(err error) bool {
+	if HasErrorCode(err, ErrorCodeAPITooManyCalls, ErrorCodeRateLimitIssues, ErrorCodeRateLimitHit, ErrorCodeSpamRateLimitHit, ErrorCodePairRateLimitHit) {
+		return true
+	}
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.HTTPStatus == http.StatusTooManyRequests
+}
+
+// IsAuthError reports whether the request failed due to authentication or permission issues
+func IsAuthError(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	if !ok {
+		return false
+	}
+	switch graphErr.Code {
+	case ErrorCodeAuthException, ErrorCodeAPIMethod, ErrorCodePermissionDenied, ErrorCodeAccessTokenExpired, ErrorCodeAccessDenied:
+		// code 0 is also the zero value, consider it only when the api reported OAuthException or 401
+		if graphErr.Code == ErrorCodeAuthException {
+			return graphErr.Type == "OAuthException" || graphErr.HTTPStatus == http.StatusUnauthorized
+		}
+		return true
+	}
+	if graphErr.Code >= ErrorCodeAPIPermissionStart && graphErr.Code <= ErrorCodeAPIPermissionEnd {
+		return true
+	}
+	return graphErr.HTTPStatus == http.StatusUnauthorized
+}
+
+// IsAccessTokenExpired reports whether the access token has expired
+func IsAccessTokenExpired(err error) bool {
+	return HasErrorCode(err, ErrorCodeAccessTokenExpired)
+}
+
+// IsReEngagementRequired reports whether the customer service window is closed
+// and only a template message can be sent
+func IsReEngagementRequired(err error) bool {
+	return HasErrorCode(err, ErrorCodeReEngagementMessage)
+}
+
+// IsRecipientNotOnWhatsApp reports whether the message could not be delivered to the recipient,
+// for example the recipient phone number is not a whatsapp phone number
+func IsRecipientNotOnWhatsApp(err error) bool {
+	return HasErrorCode(err, ErrorCodeMessageUndeliverable)
+}
+
+// IsMediaNotFound reports whether the media id is not available,
+// for example the uploaded media is expired or deleted.
+// matched on the error code and subcode, the messages are not stable.
+// media upload error (131053) is not included, returned for the unsupported or corrupt media too
+func IsMediaNotFound(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	if !ok {
+		return false
+	}
+	switch graphErr.Code {
+	case ErrorCodeInvalidParameter, ErrorCodeParameterValueInvalid:
+		return graphErr.ErrorSubcode == ErrorSubcodeObjectNotFound
+	}
+	return false
+}
+
+// IsTemplateError reports whether the request failed due to a template issue
+func IsTemplateError(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.Code >= ErrorCodeTemplateParamCountMismatch && graphErr.Code < ErrorCodeFlowBlocked
+}
+
+// IsTemporary reports whether the request failed due to a temporary server side issue
+func IsTemporary(err error) bool {
+	if HasErrorCode(err, ErrorCodeAPIUnknown, ErrorCodeAPIService, ErrorCodeServiceUnavailable, ErrorCodeServerTemporarilyUnavailable) {
+		return true
+	}
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.HTTPStatus >= http.StatusInternalServerError
+}
@@ -0,0 +1,138 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewGraphError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   GraphError
+	}{
+		{
+			name:   "graph error envelope",
+			status: http.StatusBadRequest,
+			body: `{"error":{"message":"(#131030) Recipient phone number not in allowed list","type":"OAuthException","code":131030,
+				"error_subcode":2494010,"error_data":{"messaging_product":"whatsapp","details":"Recipient phone number not in allowed list"},
+				"error_user_title":"title","error_user_msg":"user message","fbtrace_id":"trace-1"}}`,
+			want: GraphError{
+				Message:      "(#131030) Recipient phone number not in allowed list",
+				Type:         "OAuthException",
+				Code:         131030,
+				ErrorSubcode: 2494010,
+				ErrorData:    GraphErrorData{MessagingProduct: "whatsapp", Details: "Recipient phone number not in allowed list"},
+				UserTitle:    "title",
+				UserMessage:  "user message",
+				FBTraceID:    "trace-1",
+				HTTPStatus:   http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "non json body",
+			status: http.StatusBadGateway,
+			body:   "<html>bad gateway</html>",
+			want:   GraphError{Message: "502 Bad Gateway", HTTPStatus: http.StatusBadGateway, RawBody: "<html>bad gateway</html>"},
+		},
+		{
+			name:   "json without error envelope",
+			status: http.StatusInternalServerError,
+			body:   `{"status":"failed"}`,
+			want:   GraphError{Message: "500 Internal Server Error", HTTPStatus: http.StatusInternalServerError, RawBody: `{"status":"failed"}`},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusServiceUnavailable,
+			body:   "",
+			want:   GraphError{Message: "503 Service Unavailable", HTTPStatus: http.StatusServiceUnavailable},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status := fmt.Sprintf("%d %s", tc.status, http.StatusText(tc.status))
+			got := newGraphError(tc.status, status, []byte(tc.body))
+			if *got != tc.want {
+				t.Errorf("got %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGraphErrorString(t *testing.T) {
+	err := &GraphError{
+		Message:      "Invalid parameter",
+		Type:         "OAuthException",
+		Code:         100,
+		ErrorSubcode: 33,
+		FBTraceID:    "trace-1",
+		HTTPStatus:   http.StatusBadRequest,
+		ErrorData:    GraphErrorData{Details: "invalid media id"},
+	}
+	want := "graph api error [status: 400, code: 100, subcode: 33, type: OAuthException, fbtrace_id: trace-1]: Invalid parameter (invalid media id)"
+	if got := err.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGraphErrorHelpers(t *testing.T) {
+	graphErr := func(status, code int) error {
+		// wrapped, as returned by the api wrappers
+		return fmt.Errorf("error on posting: %w", &GraphError{HTTPStatus: status, Code: code})
+	}
+
+	tests := []struct {
+		name  string
+		check func(error) bool
+		err   error
+		want  bool
+	}{
+		{name: "rate limited by code", check: IsRateLimited, err: graphErr(http.StatusBadRequest, ErrorCodePairRateLimitHit), want: true},
+		{name: "rate limited by status", check: IsRateLimited, err: graphErr(http.StatusTooManyRequests, 0), want: true},
+		{name: "not rate limited", check: IsRateLimited, err: graphErr(http.StatusBadRequest, ErrorCodeInvalidParameter), want: false},
+		{name: "plain error is not rate limited", check: IsRateLimited, err: errors.New("failed"), want: false},
+		{name: "auth error by code", check: IsAuthError, err: graphErr(http.StatusBadRequest, ErrorCodeAccessTokenExpired), want: true},
+		{name: "auth error by permission range", check: IsAuthError, err: graphErr(http.StatusForbidden, 250), want: true},
+		{name: "auth error by status", check: IsAuthError, err: graphErr(http.StatusUnauthorized, 0), want: true},
+		{name: "zero code is not an auth error", check: IsAuthError, err: graphErr(http.StatusBadRequest, 0), want: false},
+		{name: "access token expired", check: IsAccessTokenExpired, err: graphErr(http.StatusUnauthorized, ErrorCodeAccessTokenExpired), want: true},
+		{name: "re-engagement required", check: IsReEngagementRequired, err: graphErr(http.StatusBadRequest, ErrorCodeReEngagementMessage), want: true},
+		{name: "recipient not on whatsapp", check: IsRecipientNotOnWhatsApp, err: graphErr(http.StatusBadRequest, ErrorCodeMessageUndeliverable), want: true},
+		{name: "template error", check: IsTemplateError, err: graphErr(http.StatusBadRequest, ErrorCodeTemplatePaused), want: true},
+		{name: "flow is not a template error", check: IsTemplateError, err: graphErr(http.StatusBadRequest, ErrorCodeFlowBlocked), want: false},
+		{name: "media upload error", check: IsMediaNotFound, err: graphErr(http.StatusBadRequest, ErrorCodeMediaUploadError), want: false},
+		{name: "media object does not exist", check: IsMediaNotFound, err: &GraphError{HTTPStatus: http.StatusBadRequest, Code: ErrorCodeInvalidParameter, ErrorSubcode: ErrorSubcodeObjectNotFound}, want: true},
+		{name: "media parameter object does not exist", check: IsMediaNotFound, err: &GraphError{HTTPStatus: http.StatusBadRequest, Code: ErrorCodeParameterValueInvalid, ErrorSubcode: ErrorSubcodeObjectNotFound}, want: true},
+		{name: "invalid media parameter without subcode", check: IsMediaNotFound, err: &GraphError{HTTPStatus: http.StatusBadRequest, Code: ErrorCodeInvalidParameter, Message: "invalid media id"}, want: false},
+		{name: "other invalid parameter", check: IsMediaNotFound, err: &GraphError{HTTPStatus: http.StatusBadRequest, Code: ErrorCodeParameterValueInvalid, ErrorData: GraphErrorData{Details: "media type not supported"}}, want: false},
+		{name: "temporary by code", check: IsTemporary, err: graphErr(http.StatusBadRequest, ErrorCodeAPIService), want: true},
+		{name: "temporary by status", check: IsTemporary, err: graphErr(http.StatusServiceUnavailable, 0), want: true},
+		{name: "not temporary", check: IsTemporary, err: graphErr(http.StatusBadRequest, ErrorCodeInvalidParameter), want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.check(tc.err); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestErrorCodeDescription(t *testing.T) {
+	tests := []struct {
+		code int
+		want string
+	}{
+		{code: ErrorCodeAccessTokenExpired, want: "access token has expired"},
+		{code: 210, want: "permission is either not granted or has been removed"},
+		{code: 999999, want: ""},
+	}
+	for _, tc := range tests {
+		if got := ErrorCodeDescription(tc.code); got != tc.want {
+			t.Errorf("ErrorCodeDescription(%d) = %q, want %q", tc.code, got, tc.want)
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
 
@@ -19,10 +22,14 @@ const (
 )
 
 type Client struct {
-	logger     *zap.Logger
-	baseURL    string
-	headers    map[string]string
-	httpClient *http.Client
+	logger       *zap.Logger
+	baseURL      string
+	headers      map[string]string
+	httpClient   *http.Client
+	retryPolicy  RetryPolicy
+	attemptHooks []AttemptHook
+	limiter      *Limiter
+	hooksMutex   sync.RWMutex
 }
 
 func New(ctx context.Context, baseURL string, headers map[string]string) (*Client, error) {
@@ -40,6 +47,16 @@ func New(ctx context.Context, baseURL string, headers map[string]string) (*Clien
 	return &_client, nil
 }
 
+// SetHTTPClient updates the http client, used to share the transport between the clients
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// HTTPClient returns the http client
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 // converts the given interface to map with json tag
 func toMap(data any) (map[string]any, error) {
 	bytes, err := json.Marshal(data)
@@ -55,72 +72,225 @@ func toMap(data any) (map[string]any, error) {
 	return mapData, nil
 }
 
-func (c *Client) getBodyAsReader(body any) (io.Reader, error) {
+// returns the request scoped logger, if available in the context
+func (c *Client) getLogger(ctx context.Context) *zap.Logger {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		return c.logger
+	}
+	return logger.Named("custom_client")
+}
+
+// BodyProvider returns a fresh request body on each call.
+// used as a request body, when the body has to be rebuilt for each attempt
+type BodyProvider func() (io.Reader, error)
+
+// SizedReader is a streaming request body with the known length
+type SizedReader struct {
+	io.ReadCloser
+	Size int64
+}
+
+// returns a body provider and the rewindable status of the body
+func (c *Client) getBodyProvider(logger *zap.Logger, body any) (BodyProvider, bool, error) {
 	if body == nil {
-		return nil, nil
+		return func() (io.Reader, error) { return nil, nil }, true, nil
 	}
 
 	switch p := body.(type) {
+	case *SizedReader:
+		// streaming body can be consumed only once
+		return func() (io.Reader, error) { return p, nil }, false, nil
+	case BodyProvider:
+		return p, true, nil
+	case func() (io.Reader, error):
+		return p, true, nil
 	case string:
-		return strings.NewReader(p), nil
+		return func() (io.Reader, error) { return strings.NewReader(p), nil }, true, nil
 	case []byte:
-		return bytes.NewReader(p), nil
+		return func() (io.Reader, error) { return bytes.NewReader(p), nil }, true, nil
+	case io.ReadSeeker:
+		start, err := p.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false, err
+		}
+		return func() (io.Reader, error) {
+			_, err := p.Seek(start, io.SeekStart)
+			return p, err
+		}, true, nil
 	case io.Reader:
-		return p, nil
+		// plain reader can be consumed only once
+		return func() (io.Reader, error) { return p, nil }, false, nil
 	default:
 		bodyBytes, err := json.Marshal(body)
 		if err != nil {
-			c.logger.Error("error on converting body to bytes", zap.Error(err))
-			return nil, err
+			logger.Error("error on converting body to bytes", zap.Error(err))
+			return nil, false, err
 		}
-		return bytes.NewReader(bodyBytes), nil
-
+		return func() (io.Reader, error) { return bytes.NewReader(bodyBytes), nil }, true, nil
 	}
 }
 
-func (c *Client) newRawRequest(requestContentType, method, path string, headers map[string]string, queryParams any, body any, out any) error {
+func (c *Client) newRawRequest(ctx context.Context, requestContentType, method, path string, headers map[string]string, queryParams any, body any, out any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := c.getLogger(ctx)
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 
-	c.logger.Debug("received request", zap.String("method", method), zap.String("url", url), zap.String("requestContentType", requestContentType))
+	logger.Debug("received request", zap.String("method", method), zap.String("url", url), zap.String("requestContentType", requestContentType))
 
-	bodyReader, err := c.getBodyAsReader(body)
+	bodyProvider, rewindable, err := c.getBodyProvider(logger, body)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(method, url, bodyReader)
+	// convert queryParameters
+	_queryParameters, err := toMap(queryParams)
 	if err != nil {
-		c.logger.Error("error on getting a new request", zap.Error(err))
+		logger.Error("error on converting queryParameters")
 		return err
 	}
-	if method == http.MethodPost && requestContentType != "" {
-		req.Header.Set("Content-Type", requestContentType)
+
+	policy := c.retryPolicy
+	maxAttempts := policy.attempts()
+	if !rewindable {
+		maxAttempts = 1
 	}
 
-	req.Header.Set("Accept", "application/json")
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		// each attempt consumes the rate limit, including the retries
+		err := c.throttleRequest(ctx)
+		if err != nil {
+			logger.Debug("request throttled", zap.String("url", url), zap.Int("attempt", attempt), zap.Error(err))
+			if lastErr != nil {
+				return errors.Join(lastErr, err)
+			}
+			return err
+		}
 
-	// include global headers
-	if len(c.headers) > 0 {
-		for k, v := range c.headers {
-			req.Header.Del(k)
-			req.Header.Set(k, v)
+		startTime := time.Now()
+		respBytes, statusCode, err := c.execute(ctx, logger, requestContentType, method, url, headers, queryParams, _queryParameters, bodyProvider)
+
+		info := AttemptInfo{
+			Method:      method,
+			URL:         url,
+			Attempt:     attempt,
+			MaxAttempts: maxAttempts,
+			StatusCode:  statusCode,
+			Duration:    time.Since(startTime),
+			Err:         err,
+		}
+		if err != nil && attempt < maxAttempts && ctx.Err() == nil && policy.shouldRetry(method, err) {
+			info.WillRetry = true
+			info.NextDelay = policy.delay(attempt, err)
+		}
+		c.runAttemptHooks(ctx, info)
+
+		if err == nil {
+			if out != nil {
+				err = json.Unmarshal(respBytes, &out)
+				if err != nil {
+					logger.Error("error on converting to target type", zap.Error(err))
+					return err
+				}
+			}
+			return nil
+		}
+
+		if !info.WillRetry {
+			return err
+		}
+		lastErr = err
+
+		logger.Debug("retrying the request", zap.String("url", url), zap.Int("attempt", attempt), zap.Duration("delay", info.NextDelay), zap.Error(err))
+		timer := time.NewTimer(info.NextDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
 		}
 	}
+}
+
+// returns the number of bytes from the current position to the end
+func remainingLength(seeker io.Seeker) (int64, error) {
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = seeker.Seek(current, io.SeekStart)
+	return end - current, err
+}
+
+// includes the global and the local headers, local headers take precedence
+func (c *Client) setHeaders(req *http.Request, headers map[string]string) {
+	// include global headers
+	for k, v := range c.headers {
+		req.Header.Del(k)
+		req.Header.Set(k, v)
+	}
 
 	// include local headers
-	if len(headers) > 0 {
-		for k, v := range headers {
-			req.Header.Del(k)
-			req.Header.Set(k, v)
+	for k, v := range headers {
+		req.Header.Del(k)
+		req.Header.Set(k, v)
+	}
+}
+
+// executes a single attempt of the request
+func (c *Client) execute(ctx context.Context, logger *zap.Logger, requestContentType, method, url string, headers map[string]string, queryParams any, _queryParameters map[string]any, bodyProvider BodyProvider) ([]byte, int, error) {
+	bodyReader, err := bodyProvider()
+	if err != nil {
+		logger.Error("error on getting a request body", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// length of the seekable sources (files, section readers) is not detected by the http client
+	contentLength := int64(-1)
+	switch p := bodyReader.(type) {
+	case *bytes.Reader, *strings.Reader, *bytes.Buffer:
+		// detected by the http client
+	case *SizedReader:
+		contentLength = p.Size
+		bodyReader = p.ReadCloser
+	case io.ReadSeeker:
+		contentLength, err = remainingLength(p)
+		if err != nil {
+			logger.Error("error on getting the body length", zap.Error(err))
+			return nil, 0, err
 		}
+		// do not let the http client close the source, it has to be reused on retry
+		bodyReader = io.NopCloser(p)
 	}
 
-	// convert queryParameters
-	_queryParameters, err := toMap(queryParams)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		c.logger.Error("error on converting queryParameters")
-		return err
+		logger.Error("error on getting a new request", zap.Error(err))
+		// release the streaming body writer
+		if closer, ok := bodyReader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return nil, 0, err
+	}
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+		if contentLength == 0 {
+			req.Body = http.NoBody
+		}
 	}
+	if method == http.MethodPost && requestContentType != "" {
+		req.Header.Set("Content-Type", requestContentType)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	c.setHeaders(req, headers)
 
 	if queryParams != nil {
 		q := req.URL.Query()
@@ -132,44 +302,38 @@ func (c *Client) newRawRequest(requestContentType, method, path string, headers
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		c.logger.Error("error on executing a request", zap.Error(err))
-		return err
+		logger.Error("error on executing a request", zap.Error(err))
+		return nil, 0, err
 	}
+	defer resp.Body.Close()
 
-	c.logger.Debug("response received", zap.String("url", url), zap.String("status", resp.Status))
+	logger.Debug("response received", zap.String("url", url), zap.String("status", resp.Status))
 
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.logger.Error("error on reading a response body", zap.Error(err))
-		return err
+		logger.Error("error on reading a response body", zap.Error(err))
+		return nil, resp.StatusCode, err
 	}
-	c.logger.Debug("received bytes", zap.String("data", string(respBytes)))
+	logger.Debug("received bytes", zap.String("data", string(respBytes)))
 
 	if resp.StatusCode != http.StatusOK {
-		c.logger.Error("request failed", zap.Int("statusCode", resp.StatusCode))
-		return fmt.Errorf("failed with status code. [status: %v, statusCode: %v]", resp.Status, resp.StatusCode)
-	}
-
-	if out != nil {
-
-		err = json.Unmarshal(respBytes, &out)
-		if err != nil {
-			c.logger.Error("error on converting to target type", zap.Error(err))
-			return err
-		}
+		graphErr := newGraphError(resp.StatusCode, resp.Status, respBytes)
+		graphErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		logger.Error("request failed", zap.Int("statusCode", resp.StatusCode), zap.Int("code", graphErr.Code), zap.Int("subcode", graphErr.ErrorSubcode), zap.String("fbtraceId", graphErr.FBTraceID))
+		return nil, resp.StatusCode, graphErr
 	}
 
-	return nil
+	return respBytes, resp.StatusCode, nil
 }
 
-func (c *Client) Get(api string, headers map[string]string, queryParams any, out any) error {
-	return c.newRawRequest(RequestContentTypeJson, http.MethodGet, api, headers, queryParams, nil, out)
+func (c *Client) Get(ctx context.Context, api string, headers map[string]string, queryParams any, out any) error {
+	return c.newRawRequest(ctx, RequestContentTypeJson, http.MethodGet, api, headers, queryParams, nil, out)
 }
 
-func (c *Client) Post(api string, headers map[string]string, queryParams any, body any, out any) error {
-	return c.newRawRequest(RequestContentTypeJson, http.MethodPost, api, headers, queryParams, body, out)
+func (c *Client) Post(ctx context.Context, api string, headers map[string]string, queryParams any, body any, out any) error {
+	return c.newRawRequest(ctx, RequestContentTypeJson, http.MethodPost, api, headers, queryParams, body, out)
 }
 
-func (c *Client) Delete(api string, headers map[string]string, queryParams any, out any) error {
-	return c.newRawRequest(RequestContentTypeJson, http.MethodDelete, api, headers, queryParams, nil, out)
+func (c *Client) Delete(ctx context.Context, api string, headers map[string]string, queryParams any, out any) error {
+	return c.newRawRequest(ctx, RequestContentTypeJson, http.MethodDelete, api, headers, queryParams, nil, out)
 }
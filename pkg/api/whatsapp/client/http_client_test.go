@@ -0,0 +1,73 @@
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(loggerUtils.WithContext(context.TODO(), zap.NewNop()), server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	return client
+}
+
+func TestClientRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		status        int
+		nonIdempotent bool
+		wantAttempts  int32
+	}{
+		{name: "get on server error", method: http.MethodGet, status: http.StatusInternalServerError, wantAttempts: 3},
+		{name: "delete on server error", method: http.MethodDelete, status: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "post on server error", method: http.MethodPost, status: http.StatusInternalServerError, wantAttempts: 1},
+		{name: "post on server error with opt-in", method: http.MethodPost, status: http.StatusInternalServerError, nonIdempotent: true, wantAttempts: 3},
+		{name: "post on too many requests", method: http.MethodPost, status: http.StatusTooManyRequests, wantAttempts: 3},
+		{name: "get on bad request", method: http.MethodGet, status: http.StatusBadRequest, wantAttempts: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := int32(0)
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(`{"error":{"message":"failed","code":100}}`))
+			})
+			policy := client.RetryPolicy()
+			policy.RetryOnStatus = DefaultRetryOnStatus
+			policy.RetryNonIdempotent = tc.nonIdempotent
+			client.SetRetryPolicy(policy)
+
+			var err error
+			switch tc.method {
+			case http.MethodGet:
+				err = client.Get(context.TODO(), "/test", nil, nil, nil)
+			case http.MethodDelete:
+				err = client.Delete(context.TODO(), "/test", nil, nil, nil)
+			case http.MethodPost:
+				err = client.Post(context.TODO(), "/test", nil, nil, map[string]string{"key": "value"}, nil)
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := atomic.LoadInt32(&attempts); got != tc.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tc.wantAttempts)
+			}
+		})
+	}
+}
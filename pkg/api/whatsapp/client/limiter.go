@@ -0,0 +1,251 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+)
+
+const (
+	DefaultMessagesPerSecond = 80
+	DefaultPairInterval      = 6 * time.Second
+
+	RateLimitModeBlock       = "block"
+	RateLimitModeNonBlocking = "non_blocking"
+
+	// stale pair buckets are removed, when the number of buckets crosses this limit
+	pairCleanupThreshold = 10000
+)
+
+// ErrWouldThrottle returned in non blocking mode, when the request has to wait for the rate limit
+var ErrWouldThrottle = errors.New("request would be throttled by the rate limiter")
+
+// token bucket, allows reservation in advance (tokens can go negative)
+type tokenBucket struct {
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+func (tb *tokenBucket) advance(now time.Time) {
+	if now.After(tb.last) {
+		tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+		tb.last = now
+	}
+}
+
+// returns the wait duration to get a token
+func (tb *tokenBucket) waitDuration(now time.Time) time.Duration {
+	tb.advance(now)
+	if tb.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tb.tokens) / tb.rate * float64(time.Second))
+}
+
+// reports the bucket is full and untouched, can be removed
+func (tb *tokenBucket) idle(now time.Time) bool {
+	tb.advance(now)
+	return tb.tokens >= tb.burst
+}
+
+// Limiter is a client side rate limiter
+// limits the throughput per phone number id and the pair rate per recipient
+type Limiter struct {
+	mutex        sync.Mutex
+	rate         float64
+	burst        int
+	pairInterval time.Duration
+	blocking     bool
+	throughput   map[string]*tokenBucket // key: phone number id
+	pairs        map[string]*tokenBucket // key: phone number id + recipient
+	now          func() time.Time
+}
+
+// NewLimiter returns a rate limiter from the config, missing values filled with defaults
+func NewLimiter(cfg types.RateLimitConfig) *Limiter {
+	rate := cfg.MessagesPerSecond
+	if rate <= 0 {
+		rate = DefaultMessagesPerSecond
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	pairInterval := cfg.PairInterval
+	if pairInterval == 0 {
+		pairInterval = DefaultPairInterval
+	}
+	return &Limiter{
+		rate:         rate,
+		burst:        burst,
+		pairInterval: pairInterval,
+		blocking:     strings.ToLower(cfg.Mode) != RateLimitModeNonBlocking,
+		throughput:   map[string]*tokenBucket{},
+		pairs:        map[string]*tokenBucket{},
+		now:          time.Now,
+	}
+}
+
+// Wait blocks until the message can be sent from the phone number id to the recipient.
+// in non blocking mode, returns ErrWouldThrottle instead of waiting.
+// the recipient can be empty, if the request is not addressed to a user
+func (l *Limiter) Wait(ctx context.Context, phoneNumberID, recipient string) error {
+	if l.blocking {
+		return l.wait(ctx, phoneNumberID, recipient)
+	}
+	return l.Allow(phoneNumberID, recipient)
+}
+
+// Allow consumes the tokens, if the message can be sent immediately, otherwise returns ErrWouldThrottle
+func (l *Limiter) Allow(phoneNumberID, recipient string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	buckets := l.getBuckets(phoneNumberID, recipient, now)
+	for _, bucket := range buckets {
+		if bucket.waitDuration(now) > 0 {
+			return ErrWouldThrottle
+		}
+	}
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return nil
+}
+
+func (l *Limiter) wait(ctx context.Context, phoneNumberID, recipient string) error {
+	l.mutex.Lock()
+	now := l.now()
+	buckets := l.getBuckets(phoneNumberID, recipient, now)
+	// reserve the tokens and wait for the longest bucket
+	delay := time.Duration(0)
+	for _, bucket := range buckets {
+		if wait := bucket.waitDuration(now); wait > delay {
+			delay = wait
+		}
+		bucket.tokens--
+	}
+	l.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// release the reservation
+		l.mutex.Lock()
+		for _, bucket := range buckets {
+			bucket.tokens = math.Min(bucket.burst, bucket.tokens+1)
+		}
+		l.mutex.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// returns the buckets applicable for the request, creates if not available
+func (l *Limiter) getBuckets(phoneNumberID, recipient string, now time.Time) []*tokenBucket {
+	buckets := []*tokenBucket{}
+
+	bucket, found := l.throughput[phoneNumberID]
+	if !found {
+		bucket = newTokenBucket(l.rate, l.burst, now)
+		l.throughput[phoneNumberID] = bucket
+	}
+	buckets = append(buckets, bucket)
+
+	recipient = NormalizePhoneNumber(recipient)
+	if recipient != "" && l.pairInterval > 0 {
+		key := phoneNumberID + ":" + recipient
+		pairBucket, found := l.pairs[key]
+		if !found {
+			if len(l.pairs) >= pairCleanupThreshold {
+				l.removeIdlePairs(now)
+			}
+			pairBucket = newTokenBucket(1/l.pairInterval.Seconds(), 1, now)
+			l.pairs[key] = pairBucket
+		}
+		buckets = append(buckets, pairBucket)
+	}
+	return buckets
+}
+
+func (l *Limiter) removeIdlePairs(now time.Time) {
+	for key, bucket := range l.pairs {
+		if bucket.idle(now) {
+			delete(l.pairs, key)
+		}
+	}
+}
+
+// NormalizePhoneNumber removes everything except digits from the phone number
+func NormalizePhoneNumber(phoneNumber string) string {
+	var sb strings.Builder
+	for _, r := range phoneNumber {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// SetLimiter updates the rate limiter of the client, nil disables rate limiting
+func (c *Client) SetLimiter(limiter *Limiter) {
+	c.limiter = limiter
+}
+
+// Limiter returns the rate limiter of the client, can be nil
+func (c *Client) Limiter() *Limiter {
+	return c.limiter
+}
+
+type rateLimitKey struct{}
+
+// identifies the rate limit buckets of a request
+type rateLimitTarget struct {
+	phoneNumberID string
+	recipient     string
+}
+
+// WithRateLimit returns a context, the requests made with it are throttled by the rate limiter of the client
+// before each attempt, retries included. the recipient can be empty, if the request is not addressed to a user
+func WithRateLimit(ctx context.Context, phoneNumberID, recipient string) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, rateLimitTarget{phoneNumberID: phoneNumberID, recipient: recipient})
+}
+
+// waits for the rate limiter, if the request is marked with WithRateLimit
+func (c *Client) throttleRequest(ctx context.Context) error {
+	target, ok := ctx.Value(rateLimitKey{}).(rateLimitTarget)
+	if !ok {
+		return nil
+	}
+	return c.Throttle(ctx, target.phoneNumberID, target.recipient)
+}
+
+// Throttle waits for the rate limiter, if configured.
+// in non blocking mode, returns ErrWouldThrottle instead of waiting
+func (c *Client) Throttle(ctx context.Context, phoneNumberID, recipient string) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx, phoneNumberID, recipient)
+}
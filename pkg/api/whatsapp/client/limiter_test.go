@@ -0,0 +1,155 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+)
+
+// returns a non blocking limiter with the controlled clock
+func newTestLimiter(cfg types.RateLimitConfig) (*Limiter, *time.Time) {
+	cfg.Mode = RateLimitModeNonBlocking
+	limiter := NewLimiter(cfg)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestLimiterAllow(t *testing.T) {
+	type step struct {
+		advance   time.Duration
+		recipient string
+		wantErr   error
+	}
+
+	tests := []struct {
+		name  string
+		cfg   types.RateLimitConfig
+		steps []step
+	}{
+		{
+			name: "throughput burst",
+			cfg:  types.RateLimitConfig{MessagesPerSecond: 2, Burst: 2, PairInterval: -1},
+			steps: []step{
+				{recipient: "1"},
+				{recipient: "2"},
+				{recipient: "3", wantErr: ErrWouldThrottle},
+				{advance: 500 * time.Millisecond, recipient: "3"},
+				{recipient: "4", wantErr: ErrWouldThrottle},
+			},
+		},
+		{
+			name: "pair interval",
+			cfg:  types.RateLimitConfig{MessagesPerSecond: 100, PairInterval: 6 * time.Second},
+			steps: []step{
+				{recipient: "+91 98765 43210"},
+				{recipient: "919876543210", wantErr: ErrWouldThrottle},
+				{recipient: "14155550100"},
+				{advance: 5 * time.Second, recipient: "919876543210", wantErr: ErrWouldThrottle},
+				{advance: time.Second, recipient: "919876543210"},
+			},
+		},
+		{
+			name: "pair interval disabled",
+			cfg:  types.RateLimitConfig{MessagesPerSecond: 100, PairInterval: -1},
+			steps: []step{
+				{recipient: "919876543210"},
+				{recipient: "919876543210"},
+			},
+		},
+		{
+			name: "empty recipient skips pair limit",
+			cfg:  types.RateLimitConfig{MessagesPerSecond: 100},
+			steps: []step{
+				{recipient: ""},
+				{recipient: ""},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter, now := newTestLimiter(tc.cfg)
+			for index, s := range tc.steps {
+				*now = now.Add(s.advance)
+				err := limiter.Wait(context.TODO(), "phone-1", s.recipient)
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: error = %v, want %v", index, err, s.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterWaitCanceled(t *testing.T) {
+	limiter := NewLimiter(types.RateLimitConfig{MessagesPerSecond: 1, Burst: 1, PairInterval: -1})
+	if err := limiter.Wait(context.TODO(), "phone-1", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "phone-1", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// the canceled reservation is released
+	limiter.mutex.Lock()
+	tokens := limiter.throughput["phone-1"].tokens
+	limiter.mutex.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("tokens = %v, reservation not released", tokens)
+	}
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "+91 98765-43210", want: "919876543210"},
+		{input: "(415) 555 0100", want: "4155550100"},
+		{input: "", want: ""},
+	}
+	for _, tc := range tests {
+		if got := NormalizePhoneNumber(tc.input); got != tc.want {
+			t.Errorf("NormalizePhoneNumber(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestClientThrottlesEachAttempt(t *testing.T) {
+	attempts := int32(0)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	policy := client.RetryPolicy()
+	policy.RetryOnStatus = DefaultRetryOnStatus
+	client.SetRetryPolicy(policy)
+
+	// allows two requests, the third attempt is throttled
+	limiter, _ := newTestLimiter(types.RateLimitConfig{MessagesPerSecond: 2, Burst: 2, PairInterval: -1})
+	client.SetLimiter(limiter)
+
+	ctx := WithRateLimit(context.TODO(), "phone-1", "919876543210")
+	err := client.Post(ctx, "/phone-1/messages", nil, nil, map[string]string{"to": "919876543210"}, nil)
+	if !errors.Is(err, ErrWouldThrottle) {
+		t.Errorf("error = %v, want %v", err, ErrWouldThrottle)
+	}
+	if _, ok := AsGraphError(err); !ok {
+		t.Errorf("error = %v, last attempt error is not included", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+
+	// requests without the rate limit target are not throttled
+	err = client.Get(context.TODO(), "/test", nil, nil, nil)
+	if errors.Is(err, ErrWouldThrottle) {
+		t.Errorf("unexpected throttle: %v", err)
+	}
+}
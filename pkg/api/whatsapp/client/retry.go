@@ -0,0 +1,246 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+)
+
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+	DefaultRetryJitter    = 0.2
+)
+
+var (
+	// DefaultRetryOnStatus is used when the status codes are not configured.
+	// the request was rejected by the server, safe to retry on all the methods
+	DefaultRetryOnStatus = []int{http.StatusTooManyRequests}
+
+	// DefaultRetryOnCodes is used when the graph error codes are not configured.
+	// throttling codes, the request was rejected by the server, safe to retry on all the methods
+	DefaultRetryOnCodes = []int{
+		ErrorCodeAPITooManyCalls,
+		ErrorCodeRateLimitIssues,
+		ErrorCodeRateLimitHit,
+		ErrorCodePairRateLimitHit,
+	}
+
+	// the request might be processed by the server, retried only on idempotent methods or on opt-in
+	transientCodes = []int{ErrorCodeAPIUnknown, ErrorCodeAPIService}
+)
+
+// RetryPolicy defines how the failed requests are retried.
+// by default, only the throttled requests (429 and the rate limit codes) and the connection failures are retried on all the methods.
+// server errors (5xx), network errors and the transient codes (1, 2) are retried only on the idempotent methods,
+// POST (example: send message) is not retried on them, unless RetryNonIdempotent is enabled
+type RetryPolicy struct {
+	MaxAttempts   int // includes the first attempt, 0 or 1 disables retry
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	Jitter        float64 // 0.0 to 1.0, randomize the delay by this fraction, 0 disables
+	RetryOnStatus []int   // http status codes, retried on all the methods
+	RetryOnCodes  []int   // graph api error codes, retried on all the methods
+	// retries the non idempotent requests (POST) on 5xx and network errors.
+	// the server might have processed the failed request, can send duplicate messages
+	RetryNonIdempotent bool
+}
+
+// AttemptInfo reports the details of a single request attempt
+type AttemptInfo struct {
+	Method      string
+	URL         string
+	Attempt     int // starts from 1
+	MaxAttempts int
+	StatusCode  int // 0, if no response received
+	Duration    time.Duration
+	Err         error
+	WillRetry   bool
+	NextDelay   time.Duration // delay before the next attempt, if WillRetry
+}
+
+// AttemptHook is called after each attempt, can be used for logging and metrics
+type AttemptHook func(ctx context.Context, info AttemptInfo)
+
+// NewRetryPolicy returns a retry policy from the config, missing values filled with defaults
+func NewRetryPolicy(cfg types.RetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts:   cfg.MaxAttempts,
+		BaseDelay:     cfg.BaseDelay,
+		MaxDelay:      cfg.MaxDelay,
+		Jitter:        DefaultRetryJitter,
+		RetryOnStatus: cfg.RetryOnStatus,
+		RetryOnCodes:  cfg.RetryOnCodes,
+
+		RetryNonIdempotent: cfg.RetryNonIdempotent,
+	}
+	if cfg.Jitter != nil {
+		policy.Jitter = *cfg.Jitter
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultRetryMaxDelay
+	}
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	} else if policy.Jitter > 1 {
+		policy.Jitter = 1
+	}
+	if len(policy.RetryOnStatus) == 0 {
+		policy.RetryOnStatus = DefaultRetryOnStatus
+	}
+	if len(policy.RetryOnCodes) == 0 {
+		policy.RetryOnCodes = DefaultRetryOnCodes
+	}
+	return policy
+}
+
+func (rp RetryPolicy) attempts() int {
+	if rp.MaxAttempts < 1 {
+		return 1
+	}
+	return rp.MaxAttempts
+}
+
+// reports the failed request can be sent again.
+// rejected requests (throttling) and connection failures are retried on all the methods,
+// server and network errors are retried only on the idempotent methods, unless enabled for all
+func (rp RetryPolicy) shouldRetry(method string, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// the request was not sent
+	if isDialError(err) {
+		return true
+	}
+
+	graphErr, isGraphErr := AsGraphError(err)
+	if isGraphErr {
+		if containsInt(rp.RetryOnStatus, graphErr.HTTPStatus) {
+			return true
+		}
+		// zero is a valid code, but not a transient one
+		if graphErr.Code != 0 && containsInt(rp.RetryOnCodes, graphErr.Code) {
+			return true
+		}
+	}
+
+	if !rp.RetryNonIdempotent && !isIdempotent(method) {
+		return false
+	}
+
+	if !isGraphErr {
+		// network level errors
+		return true
+	}
+	if graphErr.HTTPStatus >= http.StatusInternalServerError {
+		return true
+	}
+	return graphErr.Code != 0 && containsInt(transientCodes, graphErr.Code)
+}
+
+// reports the error happened before the request was sent
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the delay before the next attempt
+// exponential backoff with jitter, the "Retry-After" header takes precedence, capped by the max delay
+func (rp RetryPolicy) delay(attempt int, err error) time.Duration {
+	if graphErr, ok := AsGraphError(err); ok && graphErr.RetryAfter > 0 {
+		if rp.MaxDelay > 0 {
+			return min(graphErr.RetryAfter, rp.MaxDelay)
+		}
+		return graphErr.RetryAfter
+	}
+
+	backoff := float64(rp.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if backoff > float64(rp.MaxDelay) {
+		backoff = float64(rp.MaxDelay)
+	}
+	if rp.Jitter > 0 {
+		// randomize in the range of [backoff - jitter, backoff + jitter]
+		backoff += backoff * rp.Jitter * (rand.Float64()*2 - 1)
+	}
+	if backoff > float64(rp.MaxDelay) {
+		backoff = float64(rp.MaxDelay)
+	}
+	return time.Duration(backoff)
+}
+
+// parses the "Retry-After" header, supports delay seconds and http date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// SetRetryPolicy updates the retry policy of the client
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// RetryPolicy returns the retry policy of the client
+func (c *Client) RetryPolicy() RetryPolicy {
+	return c.retryPolicy
+}
+
+// AddAttemptHook registers a hook, called after each request attempt
+func (c *Client) AddAttemptHook(hook AttemptHook) {
+	c.hooksMutex.Lock()
+	defer c.hooksMutex.Unlock()
+	c.attemptHooks = append(c.attemptHooks, hook)
+}
+
+func (c *Client) runAttemptHooks(ctx context.Context, info AttemptInfo) {
+	c.hooksMutex.RLock()
+	hooks := c.attemptHooks
+	c.hooksMutex.RUnlock()
+	for _, hook := range hooks {
+		hook(ctx, info)
+	}
+}
@@ -0,0 +1,139 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+)
+
+func TestNewRetryPolicyJitter(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name   string
+		jitter *float64
+		want   float64
+	}{
+		{name: "not configured", jitter: nil, want: DefaultRetryJitter},
+		{name: "disabled", jitter: float(0), want: 0},
+		{name: "negative", jitter: float(-1), want: 0},
+		{name: "configured", jitter: float(0.5), want: 0.5},
+		{name: "above max", jitter: float(2), want: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := NewRetryPolicy(types.RetryConfig{Jitter: tc.jitter})
+			if policy.Jitter != tc.want {
+				t.Errorf("jitter = %v, want %v", policy.Jitter, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	graphErr := func(status, code int) error {
+		return &GraphError{HTTPStatus: status, Code: code}
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		err           error
+		nonIdempotent bool
+		want          bool
+	}{
+		{name: "context canceled", method: http.MethodGet, err: context.Canceled, want: false},
+		{name: "deadline exceeded", method: http.MethodGet, err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), want: false},
+		{name: "dial error on post", method: http.MethodPost, err: dialErr, want: true},
+		{name: "dns error on post", method: http.MethodPost, err: &net.DNSError{Err: "no such host"}, want: true},
+		{name: "read error on get", method: http.MethodGet, err: readErr, want: true},
+		{name: "read error on post", method: http.MethodPost, err: readErr, want: false},
+		{name: "read error on post with opt-in", method: http.MethodPost, err: readErr, nonIdempotent: true, want: true},
+		{name: "429 on post", method: http.MethodPost, err: graphErr(http.StatusTooManyRequests, 0), want: true},
+		{name: "throttling code on post", method: http.MethodPost, err: graphErr(http.StatusBadRequest, ErrorCodeRateLimitHit), want: true},
+		{name: "pair rate limit on post", method: http.MethodPost, err: graphErr(http.StatusBadRequest, ErrorCodePairRateLimitHit), want: true},
+		{name: "500 on get", method: http.MethodGet, err: graphErr(http.StatusInternalServerError, 0), want: true},
+		{name: "500 on delete", method: http.MethodDelete, err: graphErr(http.StatusBadGateway, 0), want: true},
+		{name: "500 on post", method: http.MethodPost, err: graphErr(http.StatusInternalServerError, 0), want: false},
+		{name: "500 on post with opt-in", method: http.MethodPost, err: graphErr(http.StatusInternalServerError, 0), nonIdempotent: true, want: true},
+		{name: "service code on get", method: http.MethodGet, err: graphErr(http.StatusBadRequest, ErrorCodeAPIService), want: true},
+		{name: "service code on post", method: http.MethodPost, err: graphErr(http.StatusBadRequest, ErrorCodeAPIService), want: false},
+		{name: "invalid parameter on get", method: http.MethodGet, err: graphErr(http.StatusBadRequest, ErrorCodeInvalidParameter), want: false},
+		{name: "auth error on get", method: http.MethodGet, err: graphErr(http.StatusUnauthorized, ErrorCodeAccessTokenExpired), want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := NewRetryPolicy(types.RetryConfig{RetryNonIdempotent: tc.nonIdempotent})
+			if got := policy.shouldRetry(tc.method, tc.err); got != tc.want {
+				t.Errorf("shouldRetry = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{name: "first attempt", attempt: 1, err: errors.New("failed"), want: 100 * time.Millisecond},
+		{name: "second attempt", attempt: 2, err: errors.New("failed"), want: 200 * time.Millisecond},
+		{name: "fourth attempt", attempt: 4, err: errors.New("failed"), want: 800 * time.Millisecond},
+		{name: "capped by max delay", attempt: 10, err: errors.New("failed"), want: time.Second},
+		{name: "retry after header", attempt: 1, err: &GraphError{RetryAfter: 500 * time.Millisecond}, want: 500 * time.Millisecond},
+		{name: "retry after capped by max delay", attempt: 1, err: &GraphError{RetryAfter: 5 * time.Minute}, want: time.Second},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.delay(tc.attempt, tc.err); got != tc.want {
+				t.Errorf("delay = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		got := policy.delay(1, errors.New("failed"))
+		if got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("delay = %v, out of the jitter range", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "empty", value: "", min: 0, max: 0},
+		{name: "seconds", value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{name: "negative", value: "-3", min: 0, max: 0},
+		{name: "invalid", value: "soon", min: 0, max: 0},
+		{name: "past date", value: "Mon, 02 Jan 2006 15:04:05 GMT", min: 0, max: 0},
+		{name: "future date", value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 55 * time.Second, max: time.Minute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseRetryAfter(tc.value)
+			if got < tc.min || got > tc.max {
+				t.Errorf("parseRetryAfter = %v, want in [%v, %v]", got, tc.min, tc.max)
+			}
+		})
+	}
+}
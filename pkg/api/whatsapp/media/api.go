@@ -1,9 +1,10 @@
 package media
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,8 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
 	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
@@ -19,10 +22,18 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	ErrSizeMismatch     = errors.New("downloaded media size mismatch")
+	ErrChecksumMismatch = errors.New("downloaded media sha256 mismatch")
+)
+
 type MediaAPI struct {
 	logger        *zap.Logger
 	phoneNumberID string
 	client        *customClient.Client
+	validate      bool
+	cache         Store
+	cacheTTL      time.Duration
 }
 
 func New(ctx context.Context, client *customClient.Client, phoneNumberID string) *MediaAPI {
@@ -34,120 +45,352 @@ func New(ctx context.Context, client *customClient.Client, phoneNumberID string)
 		phoneNumberID: phoneNumberID,
 		client:        client,
 		logger:        logger.Named("media_api"),
+		validate:      true,
 	}
 }
 
-func (m *MediaAPI) getMediaPayloadBody(media *whatsappTY.Media) ([]byte, string, error) {
-	// verify the file path or fileBytes should present
-	if len(media.FileBytes) == 0 && len(media.File) == 0 {
-		return nil, "", errors.New("either file (path to file) or fileBytes should be present")
+// SetCache enables the media id cache, identical contents are uploaded only once within the ttl.
+// zero ttl uses the DefaultCacheTTL, nil store disables the cache
+func (m *MediaAPI) SetCache(store Store, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
 	}
+	m.cache = store
+	m.cacheTTL = ttl
+}
 
-	// verify the file name
-	if len(media.Filename) == 0 {
-		return nil, "", errors.New("filename can not be empty")
-	}
+// SetValidation enables or disables the media type and size validation before uploading, enabled by default
+func (m *MediaAPI) SetValidation(enabled bool) {
+	m.validate = enabled
+}
 
-	// create a body buffer
-	var body bytes.Buffer
+// returns the size of the media source, -1 if unknown
+func mediaSize(media *whatsappTY.Media) (int64, error) {
+	switch {
+	case media.Open != nil, media.Reader != nil:
+		if media.Size > 0 {
+			return media.Size, nil
+		}
+		return -1, nil
+	case len(media.FileBytes) > 0:
+		return int64(len(media.FileBytes)), nil
+	default:
+		stat, err := os.Stat(media.File)
+		if err != nil {
+			return 0, fmt.Errorf("error on getting file info[%s]: %w", media.File, err)
+		}
+		return stat.Size(), nil
+	}
+}
 
-	// create multi part writer
-	writer := multipart.NewWriter(&body)
+// returns a function to open the media source, nil if the source can not be reopened
+func mediaOpener(media *whatsappTY.Media) func() (io.ReadCloser, error) {
+	switch {
+	case media.Open != nil:
+		return media.Open
+	case len(media.FileBytes) > 0:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(media.FileBytes)), nil
+		}
+	case media.File != "":
+		return func() (io.ReadCloser, error) {
+			file, err := os.Open(media.File)
+			if err != nil {
+				return nil, fmt.Errorf("error on opening a file[%s]: %w", media.File, err)
+			}
+			return file, nil
+		}
+	case media.Reader != nil:
+		seeker, ok := media.Reader.(io.ReadSeeker)
+		if !ok {
+			return nil
+		}
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil
+		}
+		return func() (io.ReadCloser, error) {
+			_, err := seeker.Seek(start, io.SeekStart)
+			return io.NopCloser(seeker), err
+		}
+	}
+	return nil
+}
 
+// writes the multipart payload, the source is copied between the file part and the fields
+func writeMediaPayload(writer *multipart.Writer, media *whatsappTY.Media, source io.Reader) error {
 	// set filename header
 	header := make(textproto.MIMEHeader)
 	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=file; filename="%s"`, media.Filename))
 
 	// set content type header
-	contentType := mime.TypeByExtension(filepath.Ext(media.Filename))
+	contentType := media.MediaType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(media.Filename))
+	}
 	header.Set("Content-Type", contentType)
 
 	part, err := writer.CreatePart(header)
 	if err != nil {
-		return nil, "", fmt.Errorf("error on creating part: %w", err)
+		return fmt.Errorf("error on creating part: %w", err)
 	}
 
-	// source data reader
-	var sourceReader io.Reader
-
-	// if filebytes present, use that source data
-	if len(media.FileBytes) > 0 {
-		sourceReader = bytes.NewReader(media.FileBytes)
-	} else { // otherwise, open the file and convert it to buffered reader
-		file, err := os.Open(media.File)
+	// copy the source bytes
+	if source != nil {
+		_, err = io.Copy(part, source)
 		if err != nil {
-			return nil, "", fmt.Errorf("error on opening a file[%s]: %w", media.File, err)
+			return fmt.Errorf("error on copying source data: %w", err)
 		}
-		sourceReader = bufio.NewReader(file)
-		defer func() {
-			err := file.Close()
-			if err != nil {
-				m.logger.Error("error on closing a file", zap.String("file", media.Filename), zap.Error(err))
-			}
-		}()
-	}
-
-	// copy the source bytes
-	_, err = io.Copy(part, sourceReader)
-	if err != nil {
-		return nil, "", fmt.Errorf("error on copying source data: %w", err)
 	}
 
 	// update media type
 	err = writer.WriteField("type", string(media.MediaType))
 	if err != nil {
-		return nil, "", fmt.Errorf("error on setting type: %w", err)
+		return fmt.Errorf("error on setting type: %w", err)
 	}
 
 	// update messaging_product
 	err = writer.WriteField("messaging_product", media.MessagingProduct)
 	if err != nil {
-		return nil, "", fmt.Errorf("error on setting messaging_product: %w", err)
+		return fmt.Errorf("error on setting messaging_product: %w", err)
 	}
 
 	// update filename
 	//  see: https://stackoverflow.com/questions/58024665/how-to-set-filename-parameter-in-whatsapp-business-api-while-sending-document-at
 	// err = writer.WriteField("filename", media.Filename)
 	// if err != nil {
-	// 	return nil, "", fmt.Errorf("error on setting filename: %w", err)
+	// 	return fmt.Errorf("error on setting filename: %w", err)
 	// }
 
 	err = writer.Close()
 	if err != nil {
-		return nil, "", fmt.Errorf("error on closing writer: %w", err)
+		return fmt.Errorf("error on closing writer: %w", err)
+	}
+	return nil
+}
+
+// counts the bytes read from the source and reports the progress
+type progressReader struct {
+	reader   io.Reader
+	uploaded int64
+	total    int64
+	progress func(uploaded, total int64)
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	if n > 0 {
+		pr.uploaded += int64(n)
+		pr.progress(pr.uploaded, pr.total)
+	}
+	return n, err
+}
+
+// returns the multipart payload body and the content type.
+// the payload is streamed through a pipe, the source is not buffered in memory.
+// the body is rebuilt on each attempt, if the source can be reopened
+func (m *MediaAPI) getMediaPayloadBody(ctx context.Context, media *whatsappTY.Media) (any, string, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, m.logger)
+
+	// verify the source should present
+	if len(media.FileBytes) == 0 && len(media.File) == 0 && media.Open == nil && media.Reader == nil {
+		return nil, "", errors.New("either file (path to file), fileBytes, open or reader should be present")
+	}
+
+	// verify the file name
+	if len(media.Filename) == 0 {
+		return nil, "", errors.New("filename can not be empty")
+	}
+
+	size, err := mediaSize(media)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the caller media is not modified
+	_media := *media
+	media = &_media
+
+	// limit of the sources with unknown size, verified while streaming
+	var limit int64
+	if m.validate {
+		head, err := readMediaHead(media)
+		if err != nil {
+			return nil, "", err
+		}
+		mimeType, sizeLimit, err := validateMedia(media, head, size)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid media: %w", err)
+		}
+		if media.MediaType == "" {
+			media.MediaType = mimeType
+		}
+		if size < 0 {
+			limit = sizeLimit
+		}
+	} else if media.MediaType == "" {
+		media.MediaType = mime.TypeByExtension(filepath.Ext(media.Filename))
+	}
+
+	// same boundary on all the attempts, used in the content type header
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+
+	// length of the payload, computed from the multipart overhead
+	contentLength := int64(-1)
+	if size >= 0 {
+		overhead := &bytes.Buffer{}
+		writer := multipart.NewWriter(overhead)
+		err = writer.SetBoundary(boundary)
+		if err != nil {
+			return nil, "", err
+		}
+		err = writeMediaPayload(writer, media, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		contentLength = int64(overhead.Len()) + size
+	}
+
+	// streams the payload of a single attempt
+	newBody := func(source io.ReadCloser) *customClient.SizedReader {
+		var sourceReader io.Reader = source
+		if limit > 0 {
+			sourceReader = &limitReader{reader: sourceReader, err: &SizeLimitError{Filename: media.Filename, MimeType: media.MediaType, Category: MediaCategory(media.MediaType), Limit: limit}}
+		}
+		if media.Progress != nil {
+			sourceReader = &progressReader{reader: sourceReader, total: max(size, 0), progress: media.Progress}
+		}
+
+		pipeReader, pipeWriter := io.Pipe()
+		writer := multipart.NewWriter(pipeWriter)
+		// boundary is valid, verified on computing the length
+		_ = writer.SetBoundary(boundary)
+
+		go func() {
+			err := writeMediaPayload(writer, media, sourceReader)
+			if closeErr := source.Close(); closeErr != nil {
+				logger.Error("error on closing the media source", zap.String("file", media.Filename), zap.Error(closeErr))
+			}
+			// nil error closes the pipe with EOF
+			pipeWriter.CloseWithError(err)
+		}()
+
+		return &customClient.SizedReader{ReadCloser: pipeReader, Size: contentLength}
+	}
+
+	contentType := fmt.Sprintf("multipart/form-data; boundary=%s", boundary)
+
+	opener := mediaOpener(media)
+	if opener == nil {
+		// source can be read only once, no retry
+		return newBody(io.NopCloser(media.Reader)), contentType, nil
+	}
+
+	body := customClient.BodyProvider(func() (io.Reader, error) {
+		source, err := opener()
+		if err != nil {
+			return nil, err
+		}
+		return newBody(source), nil
+	})
+	return body, contentType, nil
+}
+
+// Upload uploads the media, returns the cached media id if the same content was uploaded already
+func (m *MediaAPI) Upload(ctx context.Context, media *whatsappTY.Media) (*whatsappTY.Media, error) {
+	return m.uploadCached(ctx, media, false)
+}
+
+// WithReupload uploads the media and calls the send with the media id.
+// if the send fails with media not found error (expired or deleted media),
+// the cached media id is dropped, the media is uploaded again and the send is called once again
+func (m *MediaAPI) WithReupload(ctx context.Context, media *whatsappTY.Media, send func(ctx context.Context, mediaID string) error) error {
+	logger := loggerUtils.FromContextOrDefault(ctx, m.logger)
+
+	out, err := m.uploadCached(ctx, media, false)
+	if err != nil {
+		return err
+	}
+	err = send(ctx, out.ID)
+	if err == nil || !customClient.IsMediaNotFound(err) {
+		return err
 	}
 
-	return body.Bytes(), writer.FormDataContentType(), nil
+	logger.Info("media not found, uploading again", zap.String("mediaId", out.ID), zap.String("filename", media.Filename), zap.Error(err))
+	out, err = m.uploadCached(ctx, media, true)
+	if err != nil {
+		return err
+	}
+	return send(ctx, out.ID)
 }
 
-func (m *MediaAPI) Upload(media *whatsappTY.Media) (*whatsappTY.Media, error) {
+// uploads the media through the cache, refresh drops the cached media id
+func (m *MediaAPI) uploadCached(ctx context.Context, media *whatsappTY.Media, refresh bool) (*whatsappTY.Media, error) {
+	if m.cache == nil {
+		return m.upload(ctx, media)
+	}
+	logger := loggerUtils.FromContextOrDefault(ctx, m.logger)
+
+	hash, ok, err := contentHash(media)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// non seekable reader can not be hashed without buffering
+		return m.upload(ctx, media)
+	}
+	key := m.cacheKey(hash)
+
+	if !refresh {
+		entry, err := m.cache.Get(ctx, key)
+		if err != nil {
+			logger.Warn("error on getting media id from cache", zap.String("key", key), zap.Error(err))
+		} else if entry != nil && !entry.Expired(time.Now()) {
+			logger.Debug("media id found in cache", zap.String("key", key), zap.String("mediaId", entry.MediaID))
+			return &whatsappTY.Media{ID: entry.MediaID}, nil
+		}
+	}
+
+	out, err := m.upload(ctx, media)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := CacheEntry{Key: key, MediaID: out.ID, ExpiresAt: time.Now().Add(m.cacheTTL)}
+	if err := m.cache.Set(ctx, entry); err != nil {
+		logger.Warn("error on updating media id in cache", zap.String("key", key), zap.Error(err))
+	}
+	return out, nil
+}
+
+func (m *MediaAPI) upload(ctx context.Context, media *whatsappTY.Media) (*whatsappTY.Media, error) {
 	// /{{Phone-Number-ID}}/media
 	api := fmt.Sprintf("/%s/media", m.phoneNumberID)
 	out := &whatsappTY.Media{}
 
-	body, contentType, err := m.getMediaPayloadBody(media)
+	body, contentType, err := m.getMediaPayloadBody(ctx, media)
 	if err != nil {
 		return nil, err
 	}
 	headers := map[string]string{"Content-Type": contentType}
 
-	err = m.client.Post(api, headers, nil, body, out)
+	err = m.client.Post(ctx, api, headers, nil, body, out)
 	return out, err
 }
 
-func (m *MediaAPI) Retrieve(mediaID string) (*whatsappTY.Media, error) {
+func (m *MediaAPI) Retrieve(ctx context.Context, mediaID string) (*whatsappTY.Media, error) {
 	// /{{Media-ID}}?phone_number_id=<PHONE_NUMBER_ID>
 	api := fmt.Sprintf("/%s", mediaID)
 	out := &whatsappTY.Media{}
-	err := m.client.Get(api, nil, nil, out)
+	err := m.client.Get(ctx, api, nil, nil, out)
 	return out, err
 }
 
-func (m *MediaAPI) Delete(mediaID string) error {
+func (m *MediaAPI) Delete(ctx context.Context, mediaID string) error {
 	// /{{Media-ID}}/?phone_number_id=<PHONE_NUMBER_ID>
 	api := fmt.Sprintf("/%s", mediaID)
 	out := &whatsappTY.StatusResponse{}
-	err := m.client.Delete(api, nil, nil, out)
+	err := m.client.Delete(ctx, api, nil, nil, out)
 	if err != nil {
 		return err
 	}
@@ -159,10 +402,71 @@ func (m *MediaAPI) Delete(mediaID string) error {
 	return nil
 }
 
-func (m *MediaAPI) Download(mediaURL string) ([]byte, error) {
-	// /{{Media-URL}}
-	api := fmt.Sprintf("/%s", mediaURL)
-	out := []byte{}
-	err := m.client.Get(api, nil, nil, &out)
-	return out, err
+// Download retrieves the media url and streams the media content into the writer.
+// the size and the sha256 checksum are verified against the retrieve response,
+// returns the media details, includes the mime type
+func (m *MediaAPI) Download(ctx context.Context, mediaID string, w io.Writer) (*whatsappTY.Media, error) {
+	media, err := m.Retrieve(ctx, mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("error on retrieving media url: %w", err)
+	}
+	if media.URL == "" {
+		return nil, fmt.Errorf("media url not available, mediaId:%s", mediaID)
+	}
+
+	hash := sha256.New()
+	written, err := m.client.Download(ctx, media.URL, nil, io.MultiWriter(w, hash))
+	if err != nil {
+		return nil, fmt.Errorf("error on downloading media, mediaId:%s: %w", mediaID, err)
+	}
+
+	// verify the content
+	if media.FileSize > 0 && written != media.FileSize {
+		return nil, fmt.Errorf("%w, mediaId:%s, expected:%d, received:%d", ErrSizeMismatch, mediaID, media.FileSize, written)
+	}
+	if media.SHA256 != "" {
+		checksum := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(checksum, media.SHA256) {
+			return nil, fmt.Errorf("%w, mediaId:%s, expected:%s, received:%s", ErrChecksumMismatch, mediaID, media.SHA256, checksum)
+		}
+	}
+
+	return media, nil
+}
+
+// DownloadFile downloads the media into the file path.
+// the content is written into a temporary file and moved to the path on successful verification
+func (m *MediaAPI) DownloadFile(ctx context.Context, mediaID, path string) (*whatsappTY.Media, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, m.logger)
+
+	file, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s-*", filepath.Base(path)))
+	if err != nil {
+		return nil, fmt.Errorf("error on creating a temporary file: %w", err)
+	}
+	tmpPath := file.Name()
+
+	// removes the temporary file on failure
+	cleanup := func() {
+		if err := os.Remove(tmpPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Error("error on removing a temporary file", zap.String("file", tmpPath), zap.Error(err))
+		}
+	}
+
+	media, err := m.Download(ctx, mediaID, file)
+	closeErr := file.Close()
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	if closeErr != nil {
+		cleanup()
+		return nil, fmt.Errorf("error on closing a file[%s]: %w", tmpPath, closeErr)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("error on moving the file[%s]: %w", path, err)
+	}
+	return media, nil
 }
@@ -0,0 +1,287 @@
+package media
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	fileUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/file"
+)
+
+const (
+	// DefaultCacheTTL is the lifetime of the cached media ids.
+	// uploaded media is available for 30 days, one day is kept as margin
+	DefaultCacheTTL = 29 * 24 * time.Hour
+
+	// DefaultCacheMaxEntries is the number of media ids kept in the store
+	DefaultCacheMaxEntries = 10000
+)
+
+// CacheEntry maps the content hash to the uploaded media id
+type CacheEntry struct {
+	Key       string    `json:"key"` // phone number id and the sha256 of the content
+	MediaID   string    `json:"media_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports the media id is expired
+func (ce *CacheEntry) Expired(now time.Time) bool {
+	return !ce.ExpiresAt.IsZero() && !now.Before(ce.ExpiresAt)
+}
+
+// Store keeps the uploaded media ids, Get returns nil if the key is not available
+type Store interface {
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	Set(ctx context.Context, entry CacheEntry) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStore keeps the media ids in memory.
+// expired entries are dropped, the least recently used entry is evicted when the store is full
+type MemoryStore struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element // value: CacheEntry
+	order      *list.List               // front: most recently used
+	now        func() time.Time
+}
+
+// NewMemoryStore returns a memory store, zero or negative max entries uses the DefaultCacheMaxEntries
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (ms *MemoryStore) Get(ctx context.Context, key string) (*CacheEntry, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	entry, found := ms.get(key)
+	if !found {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (ms *MemoryStore) Set(ctx context.Context, entry CacheEntry) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.set(entry)
+	return nil
+}
+
+func (ms *MemoryStore) Delete(ctx context.Context, key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.remove(key)
+	return nil
+}
+
+// Len returns the number of entries, expired entries not removed yet are included
+func (ms *MemoryStore) Len() int {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.order.Len()
+}
+
+// returns the entry and marks it as recently used, drops the expired entry.
+// should be called with the lock held
+func (ms *MemoryStore) get(key string) (CacheEntry, bool) {
+	element, found := ms.entries[key]
+	if !found {
+		return CacheEntry{}, false
+	}
+	entry := element.Value.(CacheEntry)
+	if entry.Expired(ms.now()) {
+		ms.order.Remove(element)
+		delete(ms.entries, key)
+		return CacheEntry{}, false
+	}
+	ms.order.MoveToFront(element)
+	return entry, true
+}
+
+// adds or replaces the entry, evicts the expired and then the least recently used entries over the limit.
+// should be called with the lock held
+func (ms *MemoryStore) set(entry CacheEntry) {
+	if element, found := ms.entries[entry.Key]; found {
+		element.Value = entry
+		ms.order.MoveToFront(element)
+		return
+	}
+	ms.entries[entry.Key] = ms.order.PushFront(entry)
+	if ms.order.Len() <= ms.maxEntries {
+		return
+	}
+
+	ms.removeExpired()
+	for ms.order.Len() > ms.maxEntries {
+		oldest := ms.order.Back()
+		ms.order.Remove(oldest)
+		delete(ms.entries, oldest.Value.(CacheEntry).Key)
+	}
+}
+
+// reports the key was available, should be called with the lock held
+func (ms *MemoryStore) remove(key string) bool {
+	element, found := ms.entries[key]
+	if !found {
+		return false
+	}
+	ms.order.Remove(element)
+	delete(ms.entries, key)
+	return true
+}
+
+// should be called with the lock held
+func (ms *MemoryStore) removeExpired() {
+	now := ms.now()
+	for element := ms.order.Back(); element != nil; {
+		previous := element.Prev()
+		if entry := element.Value.(CacheEntry); entry.Expired(now) {
+			ms.order.Remove(element)
+			delete(ms.entries, entry.Key)
+		}
+		element = previous
+	}
+}
+
+// returns the entries from the least to the most recently used, should be called with the lock held
+func (ms *MemoryStore) list() []CacheEntry {
+	entries := make([]CacheEntry, 0, ms.order.Len())
+	for element := ms.order.Back(); element != nil; element = element.Prev() {
+		entries = append(entries, element.Value.(CacheEntry))
+	}
+	return entries
+}
+
+// FileStore keeps the media ids in memory and persists them in a json file on each change,
+// expired entries are removed on save
+type FileStore struct {
+	path  string
+	store *MemoryStore
+}
+
+// NewFileStore returns a file store, loads the entries from the file if available.
+// zero or negative max entries uses the DefaultCacheMaxEntries
+func NewFileStore(path string, maxEntries int) (*FileStore, error) {
+	fs := &FileStore{path: path, store: NewMemoryStore(maxEntries)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fs, nil
+		}
+		return nil, err
+	}
+	entries := []CacheEntry{}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("error on decoding media cache file[%s]: %w", path, err)
+	}
+	// saved from the least to the most recently used
+	for _, entry := range entries {
+		fs.store.set(entry)
+	}
+	return fs, nil
+}
+
+func (fs *FileStore) Get(ctx context.Context, key string) (*CacheEntry, error) {
+	return fs.store.Get(ctx, key)
+}
+
+func (fs *FileStore) Set(ctx context.Context, entry CacheEntry) error {
+	fs.store.mutex.Lock()
+	defer fs.store.mutex.Unlock()
+	fs.store.set(entry)
+	return fs.save()
+}
+
+func (fs *FileStore) Delete(ctx context.Context, key string) error {
+	fs.store.mutex.Lock()
+	defer fs.store.mutex.Unlock()
+	if !fs.store.remove(key) {
+		return nil
+	}
+	return fs.save()
+}
+
+// writes the entries into the file, should be called with the lock held
+func (fs *FileStore) save() error {
+	fs.store.removeExpired()
+	data, err := json.MarshalIndent(fs.store.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileUtils.WriteAtomic(fs.path, data, 0o644)
+}
+
+// returns the sha256 of the media content, false if the source can not be read twice
+func contentHash(media *whatsappTY.Media) (string, bool, error) {
+	hash := sha256.New()
+
+	switch {
+	case media.Open != nil:
+		source, err := media.Open()
+		if err != nil {
+			return "", false, err
+		}
+		defer source.Close()
+		if _, err = io.Copy(hash, source); err != nil {
+			return "", false, fmt.Errorf("error on reading media content: %w", err)
+		}
+
+	case len(media.FileBytes) > 0:
+		hash.Write(media.FileBytes)
+
+	case media.File != "":
+		file, err := os.Open(media.File)
+		if err != nil {
+			return "", false, fmt.Errorf("error on opening a file[%s]: %w", media.File, err)
+		}
+		defer file.Close()
+		if _, err = io.Copy(hash, file); err != nil {
+			return "", false, fmt.Errorf("error on reading a file[%s]: %w", media.File, err)
+		}
+
+	case media.Reader != nil:
+		seeker, ok := media.Reader.(io.ReadSeeker)
+		if !ok {
+			return "", false, nil
+		}
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", false, nil
+		}
+		if _, err = io.Copy(hash, seeker); err != nil {
+			return "", false, fmt.Errorf("error on reading media content: %w", err)
+		}
+		if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+			return "", false, err
+		}
+
+	default:
+		return "", false, nil
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), true, nil
+}
+
+// media ids are scoped to the phone number, the store can be shared between the phone numbers
+func (m *MediaAPI) cacheKey(hash string) string {
+	return m.phoneNumberID + "/" + hash
+}
@@ -0,0 +1,279 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+func TestMemoryStore(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		action  string // set, get, delete
+		key     string
+		ttl     time.Duration // set only, zero never expires
+		found   bool          // get only
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		steps      []step
+		wantLen    int
+	}{
+		{
+			name:       "get and delete",
+			maxEntries: 10,
+			steps: []step{
+				{action: "set", key: "a"},
+				{action: "get", key: "a", found: true},
+				{action: "get", key: "b", found: false},
+				{action: "delete", key: "a"},
+				{action: "get", key: "a", found: false},
+			},
+			wantLen: 0,
+		},
+		{
+			name:       "expired entry dropped",
+			maxEntries: 10,
+			steps: []step{
+				{action: "set", key: "a", ttl: time.Minute},
+				{advance: 59 * time.Second, action: "get", key: "a", found: true},
+				{advance: time.Second, action: "get", key: "a", found: false},
+			},
+			wantLen: 0,
+		},
+		{
+			name:       "least recently used evicted",
+			maxEntries: 2,
+			steps: []step{
+				{action: "set", key: "a"},
+				{action: "set", key: "b"},
+				{action: "get", key: "a", found: true},
+				{action: "set", key: "c"},
+				{action: "get", key: "b", found: false},
+				{action: "get", key: "a", found: true},
+				{action: "get", key: "c", found: true},
+			},
+			wantLen: 2,
+		},
+		{
+			name:       "expired evicted before the recently used",
+			maxEntries: 2,
+			steps: []step{
+				{action: "set", key: "a"},
+				{action: "set", key: "b", ttl: time.Minute},
+				{advance: time.Minute, action: "set", key: "c"},
+				{action: "get", key: "a", found: true},
+				{action: "get", key: "c", found: true},
+			},
+			wantLen: 2,
+		},
+		{
+			name:       "replace keeps the size",
+			maxEntries: 2,
+			steps: []step{
+				{action: "set", key: "a"},
+				{action: "set", key: "a"},
+				{action: "set", key: "b"},
+				{action: "get", key: "a", found: true},
+			},
+			wantLen: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMemoryStore(tc.maxEntries)
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			store.now = func() time.Time { return now }
+
+			for index, s := range tc.steps {
+				now = now.Add(s.advance)
+				switch s.action {
+				case "set":
+					entry := CacheEntry{Key: s.key, MediaID: "media-" + s.key}
+					if s.ttl > 0 {
+						entry.ExpiresAt = now.Add(s.ttl)
+					}
+					if err := store.Set(context.TODO(), entry); err != nil {
+						t.Fatal(err)
+					}
+				case "get":
+					entry, err := store.Get(context.TODO(), s.key)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if (entry != nil) != s.found {
+						t.Fatalf("step %d: get %s found = %v, want %v", index, s.key, entry != nil, s.found)
+					}
+					if entry != nil && entry.MediaID != "media-"+s.key {
+						t.Fatalf("step %d: media id = %s", index, entry.MediaID)
+					}
+				case "delete":
+					if err := store.Delete(context.TODO(), s.key); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if got := store.Len(); got != tc.wantLen {
+				t.Errorf("len = %d, want %d", got, tc.wantLen)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "media.json")
+	store, err := NewFileStore(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []CacheEntry{
+		{Key: "a", MediaID: "media-a"},
+		{Key: "b", MediaID: "media-b"},
+		{Key: "c", MediaID: "media-c"},
+		{Key: "expired", MediaID: "media-expired", ExpiresAt: time.Now().Add(-time.Minute)},
+	}
+	for _, entry := range entries {
+		if err := store.Set(context.TODO(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Delete(context.TODO(), "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewFileStore(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key   string
+		found bool
+	}{
+		{key: "a", found: false}, // evicted
+		{key: "b", found: false}, // deleted
+		{key: "c", found: true},
+		{key: "expired", found: false},
+	}
+	for _, tc := range tests {
+		entry, err := reloaded.Get(context.TODO(), tc.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (entry != nil) != tc.found {
+			t.Errorf("%s found = %v, want %v", tc.key, entry != nil, tc.found)
+		}
+	}
+}
+
+// returns a media api with a test server, counts the uploads
+func newTestMediaAPI(t *testing.T, handler http.HandlerFunc) *MediaAPI {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+	client, err := customClient.New(ctx, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(ctx, client, "phone-1")
+}
+
+func TestUploadCached(t *testing.T) {
+	uploads := int32(0)
+	api := newTestMediaAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		count := atomic.AddInt32(&uploads, 1)
+		fmt.Fprintf(w, `{"id":"media-%d"}`, count)
+	})
+	api.SetCache(NewMemoryStore(0), 0)
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	media := func(content []byte) *whatsappTY.Media {
+		return &whatsappTY.Media{Filename: "image.png", FileBytes: content}
+	}
+
+	tests := []struct {
+		name        string
+		media       *whatsappTY.Media
+		wantID      string
+		wantUploads int32
+	}{
+		{name: "first upload", media: media(png), wantID: "media-1", wantUploads: 1},
+		{name: "same content cached", media: media(png), wantID: "media-1", wantUploads: 1},
+		{name: "different content", media: media(append(png, 1)), wantID: "media-2", wantUploads: 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := api.Upload(context.TODO(), tc.media)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.ID != tc.wantID {
+				t.Errorf("media id = %s, want %s", out.ID, tc.wantID)
+			}
+			if got := atomic.LoadInt32(&uploads); got != tc.wantUploads {
+				t.Errorf("uploads = %d, want %d", got, tc.wantUploads)
+			}
+		})
+	}
+}
+
+func TestWithReupload(t *testing.T) {
+	notFound := &customClient.GraphError{HTTPStatus: http.StatusBadRequest, Code: customClient.ErrorCodeInvalidParameter, ErrorSubcode: customClient.ErrorSubcodeObjectNotFound}
+
+	tests := []struct {
+		name        string
+		sendErrs    []error // result per send call
+		wantErr     error
+		wantSends   int
+		wantUploads int32
+	}{
+		{name: "sent", sendErrs: []error{nil}, wantSends: 1, wantUploads: 1},
+		{name: "reuploaded on media not found", sendErrs: []error{notFound, nil}, wantSends: 2, wantUploads: 2},
+		{name: "corrupt media not reuploaded", sendErrs: []error{&customClient.GraphError{HTTPStatus: http.StatusBadRequest, Code: customClient.ErrorCodeMediaUploadError}}, wantErr: errors.New("media upload error"), wantSends: 1, wantUploads: 1},
+		{name: "other error not retried", sendErrs: []error{errors.New("failed")}, wantErr: errors.New("failed"), wantSends: 1, wantUploads: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			uploads := int32(0)
+			api := newTestMediaAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				count := atomic.AddInt32(&uploads, 1)
+				fmt.Fprintf(w, `{"id":"media-%d"}`, count)
+			})
+			api.SetCache(NewMemoryStore(0), 0)
+
+			sends := 0
+			mediaIDs := []string{}
+			err := api.WithReupload(context.TODO(), &whatsappTY.Media{Filename: "a.txt", FileBytes: []byte("hello")}, func(ctx context.Context, mediaID string) error {
+				sends++
+				mediaIDs = append(mediaIDs, mediaID)
+				return tc.sendErrs[sends-1]
+			})
+			if (err == nil) != (tc.wantErr == nil) {
+				t.Errorf("error = %v, want %v", err, tc.wantErr)
+			}
+			if sends != tc.wantSends {
+				t.Errorf("sends = %d, want %d", sends, tc.wantSends)
+			}
+			if got := atomic.LoadInt32(&uploads); got != tc.wantUploads {
+				t.Errorf("uploads = %d, want %d", got, tc.wantUploads)
+			}
+			if len(mediaIDs) == 2 && mediaIDs[0] == mediaIDs[1] {
+				t.Errorf("same media id %s used after reupload", mediaIDs[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// number of leading bytes used to sniff the content type
+const sniffLength = 512
+
+// supported mime types and the media category
+// https://developers.facebook.com/docs/whatsapp/cloud-api/reference/media#supported-media-types
+var supportedMediaTypes = map[string]string{
+	// audio
+	"audio/aac":  whatsappTY.MESSAGE_TYPE_AUDIO,
+	"audio/amr":  whatsappTY.MESSAGE_TYPE_AUDIO,
+	"audio/mpeg": whatsappTY.MESSAGE_TYPE_AUDIO,
+	"audio/mp4":  whatsappTY.MESSAGE_TYPE_AUDIO,
+	"audio/ogg":  whatsappTY.MESSAGE_TYPE_AUDIO, // opus codecs only
+
+	// document
+	"text/plain":                    whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/pdf":               whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/msword":            whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/vnd.ms-excel":      whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/vnd.ms-powerpoint": whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         whatsappTY.MESSAGE_TYPE_DOCUMENT,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": whatsappTY.MESSAGE_TYPE_DOCUMENT,
+
+	// image
+	"image/jpeg": whatsappTY.MESSAGE_TYPE_IMAGE,
+	"image/png":  whatsappTY.MESSAGE_TYPE_IMAGE,
+
+	// sticker
+	"image/webp": whatsappTY.MESSAGE_TYPE_STICKER,
+
+	// video
+	"video/3gpp": whatsappTY.MESSAGE_TYPE_VIDEO,
+	"video/mp4":  whatsappTY.MESSAGE_TYPE_VIDEO,
+}
+
+// size limit of the media categories
+var mediaSizeLimits = map[string]int64{
+	whatsappTY.MESSAGE_TYPE_AUDIO:    whatsappTY.MaxAudioSize,
+	whatsappTY.MESSAGE_TYPE_DOCUMENT: whatsappTY.MaxDocumentSize,
+	whatsappTY.MESSAGE_TYPE_IMAGE:    whatsappTY.MaxImageSize,
+	whatsappTY.MESSAGE_TYPE_STICKER:  whatsappTY.MaxStickerSize,
+	whatsappTY.MESSAGE_TYPE_VIDEO:    whatsappTY.MaxVideoSize,
+}
+
+// office formats can not be identified by the content, the container is detected
+var (
+	officeOpenXMLTypes = map[string]bool{
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+	}
+	legacyOfficeTypes = map[string]bool{
+		"application/msword":            true,
+		"application/vnd.ms-excel":      true,
+		"application/vnd.ms-powerpoint": true,
+	}
+)
+
+// UnsupportedTypeError is returned when the media content type is not supported by the cloud api
+type UnsupportedTypeError struct {
+	Filename string
+	MimeType string // detected from the content
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type[%s], filename:%s", e.MimeType, e.Filename)
+}
+
+// SizeLimitError is returned when the media size exceeds the limit of the media category
+type SizeLimitError struct {
+	Filename string
+	MimeType string
+	Category string // options: audio, document, image, sticker, video
+	Size     int64  // bytes read so far, if the size is not known upfront
+	Limit    int64
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("media size %d exceeds the %s limit %d, type:%s, filename:%s", e.Size, e.Category, e.Limit, e.MimeType, e.Filename)
+}
+
+// MediaCategory returns the media category (audio, document, image, sticker, video) of the mime type,
+// empty if the mime type is not supported
+func MediaCategory(mimeType string) string {
+	return supportedMediaTypes[baseMimeType(mimeType)]
+}
+
+// returns the mime type without the parameters
+func baseMimeType(mimeType string) string {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	return mediaType
+}
+
+// DetectMediaType sniffs the mime type from the leading bytes of the content.
+// the hint (declared mime type or the type of the file extension) is used to resolve the office formats,
+// those are detected only as zip or ole containers from the content
+func DetectMediaType(head []byte, hint string) string {
+	hint = baseMimeType(hint)
+
+	switch {
+	case bytes.HasPrefix(head, []byte("#!AMR")):
+		return "audio/amr"
+
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		brand := string(head[8:12])
+		switch {
+		case strings.HasPrefix(brand, "3g"):
+			return "video/3gpp"
+		case brand == "M4A " || brand == "M4B ":
+			return "audio/mp4"
+		case hint == "audio/mp4":
+			return hint
+		}
+		return "video/mp4"
+
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xF0 == 0xF0 && head[1]&0x06 == 0:
+		// adts frame header, layer bits are zero
+		return "audio/aac"
+
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		// mpeg audio frame header without id3 tag
+		return "audio/mpeg"
+
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		if officeOpenXMLTypes[hint] {
+			return hint
+		}
+		return "application/zip"
+
+	case bytes.HasPrefix(head, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")):
+		if legacyOfficeTypes[hint] {
+			return hint
+		}
+		return "application/x-ole-storage"
+	}
+
+	detected := baseMimeType(http.DetectContentType(head))
+	if detected == "application/ogg" {
+		return "audio/ogg"
+	}
+	return detected
+}
+
+// reports the webp image is animated, from the extended file format header
+func isAnimatedWebP(head []byte) bool {
+	return len(head) > 20 && string(head[12:16]) == "VP8X" && head[20]&0x02 != 0
+}
+
+// returns the size limit of the media content
+func sizeLimit(category string, head []byte) int64 {
+	if category == whatsappTY.MESSAGE_TYPE_STICKER && isAnimatedWebP(head) {
+		return whatsappTY.MaxAnimatedStickerSize
+	}
+	return mediaSizeLimits[category]
+}
+
+// validates the content type and the size of the media,
+// size -1 is unknown, verified while streaming.
+// returns the detected mime type and the size limit
+func validateMedia(media *whatsappTY.Media, head []byte, size int64) (string, int64, error) {
+	if size == 0 || len(head) == 0 {
+		return "", 0, &whatsappTY.ValidationError{Field: "media", Reason: "empty content"}
+	}
+
+	hint := media.MediaType
+	if hint == "" {
+		hint = mime.TypeByExtension(filepath.Ext(media.Filename))
+	}
+
+	mimeType := DetectMediaType(head, hint)
+	category := MediaCategory(mimeType)
+	if category == "" {
+		return "", 0, &UnsupportedTypeError{Filename: media.Filename, MimeType: mimeType}
+	}
+
+	if media.MediaType != "" && baseMimeType(media.MediaType) != mimeType {
+		return "", 0, &whatsappTY.ValidationError{Field: "type", Reason: fmt.Sprintf("%s does not match the content type %s", media.MediaType, mimeType)}
+	}
+
+	limit := sizeLimit(category, head)
+	if size > limit {
+		return "", 0, &SizeLimitError{Filename: media.Filename, MimeType: mimeType, Category: category, Size: size, Limit: limit}
+	}
+	return mimeType, limit, nil
+}
+
+// reads the leading bytes of the media source, used to sniff the content type.
+// the consumed bytes of a non seekable reader are replayed in front of the reader
+func readMediaHead(media *whatsappTY.Media) ([]byte, error) {
+	readHead := func(reader io.Reader) ([]byte, error) {
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(reader, head)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("error on reading media content: %w", err)
+		}
+		return head[:n], nil
+	}
+
+	switch {
+	case media.Open != nil:
+		source, err := media.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer source.Close()
+		return readHead(source)
+
+	case len(media.FileBytes) > 0:
+		return media.FileBytes[:min(len(media.FileBytes), sniffLength)], nil
+
+	case media.File != "":
+		file, err := os.Open(media.File)
+		if err != nil {
+			return nil, fmt.Errorf("error on opening a file[%s]: %w", media.File, err)
+		}
+		defer file.Close()
+		return readHead(file)
+
+	case media.Reader != nil:
+		if seeker, ok := media.Reader.(io.ReadSeeker); ok {
+			start, err := seeker.Seek(0, io.SeekCurrent)
+			if err == nil {
+				head, err := readHead(seeker)
+				if err != nil {
+					return nil, err
+				}
+				_, err = seeker.Seek(start, io.SeekStart)
+				return head, err
+			}
+		}
+		head, err := readHead(media.Reader)
+		if err != nil {
+			return nil, err
+		}
+		media.Reader = io.MultiReader(bytes.NewReader(head), media.Reader)
+		return head, nil
+	}
+	return nil, nil
+}
+
+// fails the read, when the content exceeds the size limit
+// used on the sources with unknown size
+type limitReader struct {
+	reader io.Reader
+	read   int64
+	err    *SizeLimitError
+}
+
+func (lr *limitReader) Read(p []byte) (int, error) {
+	n, err := lr.reader.Read(p)
+	lr.read += int64(n)
+	if lr.read > lr.err.Limit {
+		lr.err.Size = lr.read
+		return n, lr.err
+	}
+	return n, err
+}
@@ -0,0 +1,228 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+var (
+	pngHead  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	jpegHead = []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00")
+	pdfHead  = []byte("%PDF-1.7\n")
+	zipHead  = []byte("PK\x03\x04\x14\x00\x06\x00")
+	oleHead  = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1\x00\x00")
+	oggHead  = []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00")
+)
+
+// returns a webp header, the extended format with the animation flag if animated
+func webpHead(animated bool) []byte {
+	head := []byte("RIFF\x00\x00\x00\x00WEBPVP8 \x00\x00\x00\x00\x00")
+	if animated {
+		head = []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x00\x00\x00\x00\x02")
+	}
+	return head
+}
+
+// returns an iso media header with the brand
+func ftypHead(brand string) []byte {
+	return append([]byte("\x00\x00\x00\x18ftyp"), []byte(brand+"\x00\x00\x00\x00")...)
+}
+
+func TestDetectMediaType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		hint string
+		want string
+	}{
+		{name: "png", head: pngHead, want: "image/png"},
+		{name: "jpeg", head: jpegHead, want: "image/jpeg"},
+		{name: "webp", head: webpHead(false), want: "image/webp"},
+		{name: "pdf", head: pdfHead, want: "application/pdf"},
+		{name: "plain text", head: []byte("hello world"), want: "text/plain"},
+		{name: "amr", head: []byte("#!AMR\n"), want: "audio/amr"},
+		{name: "aac", head: []byte("\xFF\xF1\x50\x80"), want: "audio/aac"},
+		{name: "mpeg audio", head: []byte("\xFF\xFB\x90\x64"), want: "audio/mpeg"},
+		{name: "ogg", head: oggHead, want: "audio/ogg"},
+		{name: "mp4", head: ftypHead("isom"), want: "video/mp4"},
+		{name: "3gpp", head: ftypHead("3gp5"), want: "video/3gpp"},
+		{name: "m4a", head: ftypHead("M4A "), want: "audio/mp4"},
+		{name: "mp4 audio hint", head: ftypHead("isom"), hint: "audio/mp4", want: "audio/mp4"},
+		{name: "docx hint", head: zipHead, hint: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", want: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{name: "zip without office hint", head: zipHead, hint: "application/pdf", want: "application/zip"},
+		{name: "doc hint with parameters", head: oleHead, hint: "application/msword; charset=binary", want: "application/msword"},
+		{name: "ole without hint", head: oleHead, want: "application/x-ole-storage"},
+		{name: "hint ignored for content", head: pngHead, hint: "application/pdf", want: "image/png"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectMediaType(tc.head, tc.hint); got != tc.want {
+				t.Errorf("detected = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMediaCategory(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     string
+	}{
+		{mimeType: "image/png", want: whatsappTY.MESSAGE_TYPE_IMAGE},
+		{mimeType: "IMAGE/JPEG", want: whatsappTY.MESSAGE_TYPE_IMAGE},
+		{mimeType: "image/webp", want: whatsappTY.MESSAGE_TYPE_STICKER},
+		{mimeType: "audio/ogg; codecs=opus", want: whatsappTY.MESSAGE_TYPE_AUDIO},
+		{mimeType: "text/plain; charset=utf-8", want: whatsappTY.MESSAGE_TYPE_DOCUMENT},
+		{mimeType: "video/mp4", want: whatsappTY.MESSAGE_TYPE_VIDEO},
+		{mimeType: "image/gif", want: ""},
+		{mimeType: "application/zip", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.mimeType, func(t *testing.T) {
+			if got := MediaCategory(tc.mimeType); got != tc.want {
+				t.Errorf("category = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateMedia(t *testing.T) {
+	tests := []struct {
+		name      string
+		media     whatsappTY.Media
+		head      []byte
+		size      int64
+		wantType  string
+		wantLimit int64
+		wantErr   string // options: validation, unsupported, size
+	}{
+		{name: "image", media: whatsappTY.Media{Filename: "a.png"}, head: pngHead, size: 100, wantType: "image/png", wantLimit: whatsappTY.MaxImageSize},
+		{name: "unknown size", media: whatsappTY.Media{Filename: "a.png"}, head: pngHead, size: -1, wantType: "image/png", wantLimit: whatsappTY.MaxImageSize},
+		{name: "renamed file detected by content", media: whatsappTY.Media{Filename: "a.pdf"}, head: pngHead, size: 100, wantType: "image/png", wantLimit: whatsappTY.MaxImageSize},
+		{name: "docx by extension", media: whatsappTY.Media{Filename: "a.docx"}, head: zipHead, size: 100, wantType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", wantLimit: whatsappTY.MaxDocumentSize},
+		{name: "sticker", media: whatsappTY.Media{Filename: "a.webp"}, head: webpHead(false), size: whatsappTY.MaxStickerSize, wantType: "image/webp", wantLimit: whatsappTY.MaxStickerSize},
+		{name: "animated sticker", media: whatsappTY.Media{Filename: "a.webp"}, head: webpHead(true), size: whatsappTY.MaxStickerSize + 1, wantType: "image/webp", wantLimit: whatsappTY.MaxAnimatedStickerSize},
+		{name: "static sticker too large", media: whatsappTY.Media{Filename: "a.webp"}, head: webpHead(false), size: whatsappTY.MaxStickerSize + 1, wantErr: "size"},
+		{name: "image too large", media: whatsappTY.Media{Filename: "a.png"}, head: pngHead, size: whatsappTY.MaxImageSize + 1, wantErr: "size"},
+		{name: "empty content", media: whatsappTY.Media{Filename: "a.png"}, head: nil, size: 0, wantErr: "validation"},
+		{name: "unsupported zip", media: whatsappTY.Media{Filename: "a.zip"}, head: zipHead, size: 100, wantErr: "unsupported"},
+		{name: "gif unsupported", media: whatsappTY.Media{Filename: "a.gif"}, head: []byte("GIF89a"), size: 100, wantErr: "unsupported"},
+		{name: "declared type mismatch", media: whatsappTY.Media{Filename: "a.png", MediaType: "application/pdf"}, head: pngHead, size: 100, wantErr: "validation"},
+		{name: "declared type matches", media: whatsappTY.Media{Filename: "a", MediaType: "image/png"}, head: pngHead, size: 100, wantType: "image/png", wantLimit: whatsappTY.MaxImageSize},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mimeType, limit, err := validateMedia(&tc.media, tc.head, tc.size)
+
+			var validationErr *whatsappTY.ValidationError
+			var unsupportedErr *UnsupportedTypeError
+			var sizeErr *SizeLimitError
+			switch tc.wantErr {
+			case "":
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			case "validation":
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("error = %v, want validation error", err)
+				}
+				return
+			case "unsupported":
+				if !errors.As(err, &unsupportedErr) {
+					t.Fatalf("error = %v, want unsupported type error", err)
+				}
+				return
+			case "size":
+				if !errors.As(err, &sizeErr) {
+					t.Fatalf("error = %v, want size limit error", err)
+				}
+				if sizeErr.Size != tc.size {
+					t.Errorf("size = %d, want %d", sizeErr.Size, tc.size)
+				}
+				return
+			}
+			if mimeType != tc.wantType {
+				t.Errorf("mime type = %s, want %s", mimeType, tc.wantType)
+			}
+			if limit != tc.wantLimit {
+				t.Errorf("limit = %d, want %d", limit, tc.wantLimit)
+			}
+		})
+	}
+}
+
+// reader without seek support
+type plainReader struct {
+	reader io.Reader
+}
+
+func (pr *plainReader) Read(p []byte) (int, error) {
+	return pr.reader.Read(p)
+}
+
+func TestReadMediaHead(t *testing.T) {
+	content := append(append([]byte{}, pngHead...), bytes.Repeat([]byte{1}, 2*sniffLength)...)
+
+	tests := []struct {
+		name  string
+		media *whatsappTY.Media
+	}{
+		{name: "bytes", media: &whatsappTY.Media{FileBytes: content}},
+		{name: "open", media: &whatsappTY.Media{Open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(content)), nil }}},
+		{name: "seeker", media: &whatsappTY.Media{Reader: bytes.NewReader(content)}},
+		{name: "plain reader", media: &whatsappTY.Media{Reader: &plainReader{reader: bytes.NewReader(content)}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			head, err := readMediaHead(tc.media)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(head, content[:sniffLength]) {
+				t.Fatalf("head length = %d, want the first %d bytes", len(head), sniffLength)
+			}
+			if tc.media.Reader == nil {
+				return
+			}
+			// the reader should still return the full content
+			remaining, err := io.ReadAll(tc.media.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(remaining, content) {
+				t.Errorf("reader returned %d bytes, want %d", len(remaining), len(content))
+			}
+		})
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "under the limit", content: "hello", limit: 10},
+		{name: "at the limit", content: "hello", limit: 5},
+		{name: "over the limit", content: "hello world", limit: 5, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := &limitReader{reader: strings.NewReader(tc.content), err: &SizeLimitError{Limit: tc.limit}}
+			_, err := io.ReadAll(reader)
+			var sizeErr *SizeLimitError
+			if got := errors.As(err, &sizeErr); got != tc.wantErr {
+				t.Fatalf("error = %v, want size limit error %v", err, tc.wantErr)
+			}
+			if tc.wantErr && sizeErr.Size <= tc.limit {
+				t.Errorf("size = %d, want over %d", sizeErr.Size, tc.limit)
+			}
+		})
+	}
+}
@@ -2,32 +2,106 @@ package message
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
 	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
 )
 
+// ErrGuardRejected wraps the guard error, when the message is rejected before posting
+var ErrGuardRejected = errors.New("message rejected by guard")
+
+// Guard checks the message before posting, returns the message to be posted or an error to reject it.
+// used to enforce the customer service window
+type Guard interface {
+	Check(ctx context.Context, phoneNumberID string, message whatsappTY.Message) (whatsappTY.Message, error)
+}
+
+// Recorder is called after the message is posted, used to record the outbound messages
+type Recorder interface {
+	RecordSent(ctx context.Context, phoneNumberID string, message whatsappTY.Message, response *whatsappTY.MessageResponse)
+}
+
 type MessageAPI struct {
+	logger        *zap.Logger
 	phoneNumberID string
 	client        *customClient.Client
+	validate      bool
+	guard         Guard
+	recorder      Recorder
 }
 
 func New(ctx context.Context, client *customClient.Client, phoneNumberID string) *MessageAPI {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
 	return &MessageAPI{
 		phoneNumberID: phoneNumberID,
 		client:        client,
+		logger:        logger.Named("message_api"),
+		validate:      true,
 	}
 }
 
-func (ma *MessageAPI) Post(message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+// SetValidation enables or disables the message validation before posting, enabled by default
+func (ma *MessageAPI) SetValidation(enabled bool) {
+	ma.validate = enabled
+}
+
+// SetGuard sets the guard, called before posting a message. nil disables the guard
+func (ma *MessageAPI) SetGuard(guard Guard) {
+	ma.guard = guard
+}
+
+// SetRecorder sets the recorder, called after posting a message. nil disables the recorder
+func (ma *MessageAPI) SetRecorder(recorder Recorder) {
+	ma.recorder = recorder
+}
+
+func (ma *MessageAPI) Post(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, ma.logger)
 	// /{{Phone-Number-ID}}/messages
 	api := fmt.Sprintf("/%s/messages", ma.phoneNumberID)
 	out := &whatsappTY.MessageResponse{}
-	err := ma.client.Post(api, nil, nil, &message, out)
+
+	if message.MessagingProduct == "" {
+		message.MessagingProduct = whatsappTY.DEFAULT_MESSAGING_PRODUCT
+	}
+	if ma.guard != nil {
+		guarded, err := ma.guard.Check(ctx, ma.phoneNumberID, message)
+		if err != nil {
+			logger.Debug("message rejected by guard", zap.String("to", message.To), zap.String("type", message.Type), zap.Error(err))
+			return nil, fmt.Errorf("%w: %w", ErrGuardRejected, err)
+		}
+		message = guarded
+	}
+	if ma.validate {
+		err := message.Validate()
+		if err != nil {
+			logger.Debug("invalid message", zap.String("to", message.To), zap.String("type", message.Type), zap.Error(err))
+			return nil, fmt.Errorf("invalid message: %w", err)
+		}
+	}
+
+	// throttled by the client on each attempt
+	postCtx := customClient.WithRateLimit(ctx, ma.phoneNumberID, message.To)
+	err := ma.client.Post(postCtx, api, nil, nil, &message, out)
 	if err != nil {
+		logger.Debug("error on posting a message", zap.String("to", message.To), zap.String("type", message.Type), zap.Error(err))
 		return nil, err
 	}
 
+	if ma.recorder != nil {
+		ma.recorder.RecordSent(ctx, ma.phoneNumberID, message, out)
+	}
+
+	if out.IsPaced() {
+		logger.Info("template message held for quality assessment", zap.String("to", message.To), zap.String("messageId", out.MessageID()))
+	}
+
 	return out, nil
 }
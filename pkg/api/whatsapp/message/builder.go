@@ -0,0 +1,162 @@
+package message
+
+import (
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// Builder builds a message with the type matching to the populated object
+//
+//	msg := message.Text("+919876543210", "hello").ReplyTo("wamid.xxx").Build()
+type Builder struct {
+	message whatsappTY.Message
+}
+
+func newBuilder(to, messageType string) *Builder {
+	return &Builder{
+		message: whatsappTY.Message{
+			MessagingProduct: whatsappTY.DEFAULT_MESSAGING_PRODUCT,
+			RecipientType:    whatsappTY.RECIPIENT_TYPE_INDIVIDUAL,
+			To:               to,
+			Type:             messageType,
+		},
+	}
+}
+
+// MediaID returns a media object refers the uploaded media id
+func MediaID(mediaID string) *whatsappTY.MessageMediaObject {
+	return &whatsappTY.MessageMediaObject{ID: mediaID}
+}
+
+// MediaLink returns a media object refers the public url
+func MediaLink(link string) *whatsappTY.MessageMediaObject {
+	return &whatsappTY.MessageMediaObject{Link: link}
+}
+
+func Text(to, body string) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_TEXT)
+	b.message.Text = &whatsappTY.MessageTextObject{Body: body}
+	return b
+}
+
+func Image(to string, media *whatsappTY.MessageMediaObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_IMAGE)
+	b.message.Image = media
+	return b
+}
+
+func Audio(to string, media *whatsappTY.MessageMediaObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_AUDIO)
+	b.message.Audio = media
+	return b
+}
+
+func Video(to string, media *whatsappTY.MessageMediaObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_VIDEO)
+	b.message.Video = media
+	return b
+}
+
+func Document(to string, media *whatsappTY.MessageMediaObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_DOCUMENT)
+	b.message.Document = media
+	return b
+}
+
+func Sticker(to string, media *whatsappTY.MessageMediaObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_STICKER)
+	b.message.Sticker = media
+	return b
+}
+
+func Location(to string, latitude, longitude float64, name, address string) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_LOCATION)
+	b.message.Location = &whatsappTY.LocationObject{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Name:      name,
+		Address:   address,
+	}
+	return b
+}
+
+func Contacts(to string, contacts ...whatsappTY.ContactObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_CONTACTS)
+	b.message.Contacts = contacts
+	return b
+}
+
+// Reaction reacts to the given message, empty emoji removes the reaction
+func Reaction(to, messageID, emoji string) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_REACTION)
+	b.message.Reaction = &whatsappTY.MessageReactionObject{MessageID: messageID, Emoji: emoji}
+	return b
+}
+
+func Template(to string, template *whatsappTY.MessageTemplateObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_TEMPLATE)
+	b.message.Template = template
+	return b
+}
+
+func Interactive(to string, interactive *whatsappTY.InteractiveObject) *Builder {
+	b := newBuilder(to, whatsappTY.MESSAGE_TYPE_INTERACTIVE)
+	b.message.Interactive = interactive
+	return b
+}
+
+// ReplyTo sends the message as a reply to the given message id
+func (b *Builder) ReplyTo(messageID string) *Builder {
+	b.message.Context = &whatsappTY.MessageContext{MessageID: messageID}
+	return b
+}
+
+// PreviewURL renders a preview of the first url in the text message
+func (b *Builder) PreviewURL(preview bool) *Builder {
+	if b.message.Text != nil {
+		b.message.Text.PreviewURL = preview
+	}
+	return b
+}
+
+// Caption updates the caption of image, video and document, not supported on audio and sticker.
+// the media object is copied, the object of the caller is not modified
+func (b *Builder) Caption(caption string) *Builder {
+	update := func(media *whatsappTY.MessageMediaObject) *whatsappTY.MessageMediaObject {
+		if media == nil {
+			return nil
+		}
+		updated := *media
+		updated.Caption = caption
+		return &updated
+	}
+	switch b.message.Type {
+	case whatsappTY.MESSAGE_TYPE_IMAGE:
+		b.message.Image = update(b.message.Image)
+	case whatsappTY.MESSAGE_TYPE_VIDEO:
+		b.message.Video = update(b.message.Video)
+	case whatsappTY.MESSAGE_TYPE_DOCUMENT:
+		b.message.Document = update(b.message.Document)
+	}
+	return b
+}
+
+// Filename updates the filename of document, the media object is copied
+func (b *Builder) Filename(filename string) *Builder {
+	if b.message.Document != nil {
+		updated := *b.message.Document
+		updated.Filename = filename
+		b.message.Document = &updated
+	}
+	return b
+}
+
+// BizOpaqueCallbackData is returned in the status webhooks of the message
+func (b *Builder) BizOpaqueCallbackData(data string) *Builder {
+	b.message.BizOpaqueCallbackData = data
+	return b
+}
+
+// Build returns the message
+func (b *Builder) Build() whatsappTY.Message {
+	return b.message
+}
@@ -0,0 +1,112 @@
+package message
+
+import (
+	"testing"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+func TestBuilderCaption(t *testing.T) {
+	tests := []struct {
+		name        string
+		build       func(media *whatsappTY.MessageMediaObject) *Builder
+		media       func(message whatsappTY.Message) *whatsappTY.MessageMediaObject
+		wantCaption string
+	}{
+		{
+			name:        "image",
+			build:       func(media *whatsappTY.MessageMediaObject) *Builder { return Image("919876543210", media) },
+			media:       func(message whatsappTY.Message) *whatsappTY.MessageMediaObject { return message.Image },
+			wantCaption: "caption",
+		},
+		{
+			name:        "video",
+			build:       func(media *whatsappTY.MessageMediaObject) *Builder { return Video("919876543210", media) },
+			media:       func(message whatsappTY.Message) *whatsappTY.MessageMediaObject { return message.Video },
+			wantCaption: "caption",
+		},
+		{
+			name:        "document",
+			build:       func(media *whatsappTY.MessageMediaObject) *Builder { return Document("919876543210", media) },
+			media:       func(message whatsappTY.Message) *whatsappTY.MessageMediaObject { return message.Document },
+			wantCaption: "caption",
+		},
+		{
+			name:        "audio not captioned",
+			build:       func(media *whatsappTY.MessageMediaObject) *Builder { return Audio("919876543210", media) },
+			media:       func(message whatsappTY.Message) *whatsappTY.MessageMediaObject { return message.Audio },
+			wantCaption: "",
+		},
+		{
+			name:        "sticker not captioned",
+			build:       func(media *whatsappTY.MessageMediaObject) *Builder { return Sticker("919876543210", media) },
+			media:       func(message whatsappTY.Message) *whatsappTY.MessageMediaObject { return message.Sticker },
+			wantCaption: "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shared := MediaID("media-1")
+			message := tc.build(shared).Caption("caption").Build()
+
+			media := tc.media(message)
+			if media == nil || media.ID != "media-1" {
+				t.Fatalf("media object = %+v", media)
+			}
+			if media.Caption != tc.wantCaption {
+				t.Errorf("caption = %q, want %q", media.Caption, tc.wantCaption)
+			}
+			if shared.Caption != "" {
+				t.Errorf("caller media object modified, caption = %q", shared.Caption)
+			}
+			if err := message.Validate(); err != nil {
+				t.Errorf("built message is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	shared := MediaLink("https://example.com/file.pdf")
+	tests := []struct {
+		name    string
+		message whatsappTY.Message
+		check   func(t *testing.T, message whatsappTY.Message)
+	}{
+		{
+			name:    "text reply with preview",
+			message: Text("123", "see https://example.com").ReplyTo("wamid-1").PreviewURL(true).BizOpaqueCallbackData("tracking").Build(),
+			check: func(t *testing.T, message whatsappTY.Message) {
+				if message.Type != whatsappTY.MESSAGE_TYPE_TEXT || !message.Text.PreviewURL || message.Context.MessageID != "wamid-1" || message.BizOpaqueCallbackData != "tracking" {
+					t.Errorf("unexpected message: %+v", message)
+				}
+			},
+		},
+		{
+			name:    "document filename copied",
+			message: Document("123", shared).Filename("invoice.pdf").Build(),
+			check: func(t *testing.T, message whatsappTY.Message) {
+				if message.Document.Filename != "invoice.pdf" || shared.Filename != "" {
+					t.Errorf("filename = %q, caller filename = %q", message.Document.Filename, shared.Filename)
+				}
+			},
+		},
+		{
+			name:    "reaction",
+			message: Reaction("123", "wamid-1", "👍").Build(),
+			check: func(t *testing.T, message whatsappTY.Message) {
+				if message.Type != whatsappTY.MESSAGE_TYPE_REACTION || message.Reaction.MessageID != "wamid-1" {
+					t.Errorf("unexpected message: %+v", message)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.message.MessagingProduct != whatsappTY.DEFAULT_MESSAGING_PRODUCT || tc.message.To != "123" {
+				t.Errorf("defaults not set: %+v", tc.message)
+			}
+			tc.check(t, tc.message)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// MarkRead marks the received message as read
+func (ma *MessageAPI) MarkRead(ctx context.Context, messageID string) (*whatsappTY.ReadReceipt, error) {
+	return ma.postStatus(ctx, messageID, false)
+}
+
+// MarkReadWithTyping marks the received message as read and shows the typing indicator to the user.
+// the indicator is dismissed on sending a response or after 25 seconds
+func (ma *MessageAPI) MarkReadWithTyping(ctx context.Context, messageID string) (*whatsappTY.ReadReceipt, error) {
+	return ma.postStatus(ctx, messageID, true)
+}
+
+// MarkThreadRead marks the received messages of a conversation thread as read.
+// message ids are expected in the received order. whatsapp marks all the earlier messages as read,
+// when a message is marked as read, hence only the latest message is posted and its receipt is returned
+func (ma *MessageAPI) MarkThreadRead(ctx context.Context, messageIDs ...string) (*whatsappTY.ReadReceipt, error) {
+	if len(messageIDs) == 0 {
+		return nil, errors.New("message ids can not be empty")
+	}
+	return ma.MarkRead(ctx, messageIDs[len(messageIDs)-1])
+}
+
+func (ma *MessageAPI) postStatus(ctx context.Context, messageID string, typing bool) (*whatsappTY.ReadReceipt, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, ma.logger)
+	if messageID == "" {
+		return nil, errors.New("message id can not be empty")
+	}
+
+	// /{{Phone-Number-ID}}/messages
+	api := fmt.Sprintf("/%s/messages", ma.phoneNumberID)
+	request := whatsappTY.MessageStatusRequest{
+		MessagingProduct: whatsappTY.DEFAULT_MESSAGING_PRODUCT,
+		Status:           whatsappTY.MESSAGE_STATUS_READ,
+		MessageID:        messageID,
+	}
+	if typing {
+		request.TypingIndicator = &whatsappTY.TypingIndicator{Type: whatsappTY.TYPING_INDICATOR_TEXT}
+	}
+
+	out := &whatsappTY.StatusResponse{}
+	err := ma.client.Post(ctx, api, nil, nil, &request, out)
+	if err != nil {
+		logger.Debug("error on marking the message as read", zap.String("messageId", messageID), zap.Error(err))
+		return nil, err
+	}
+
+	return &whatsappTY.ReadReceipt{MessageID: messageID, Success: out.Success}, nil
+}
@@ -0,0 +1,150 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// records the status requests, responds with the success status
+type statusServer struct {
+	mutex    sync.Mutex
+	requests []map[string]any
+	success  bool
+	fail     bool
+}
+
+func (ss *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if r.Method != http.MethodPost || r.URL.Path != "/phone-1/messages" {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"message":"not found","code":100}}`)
+		return
+	}
+	body := map[string]any{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	ss.requests = append(ss.requests, body)
+	if ss.fail {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"invalid message id","code":100}}`)
+		return
+	}
+	fmt.Fprintf(w, `{"success":%t}`, ss.success)
+}
+
+func newTestMessageAPI(t *testing.T, server http.Handler) *MessageAPI {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+	client, err := customClient.New(ctx, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(ctx, client, "phone-1")
+}
+
+func TestMarkRead(t *testing.T) {
+	readRequest := func(messageID string) map[string]any {
+		return map[string]any{"messaging_product": "whatsapp", "status": "read", "message_id": messageID}
+	}
+	withTyping := func(request map[string]any) map[string]any {
+		request["typing_indicator"] = map[string]any{"type": "text"}
+		return request
+	}
+
+	tests := []struct {
+		name         string
+		call         func(api *MessageAPI) (*whatsappTY.ReadReceipt, error)
+		server       *statusServer
+		want         *whatsappTY.ReadReceipt
+		wantErr      bool
+		wantRequests []map[string]any
+	}{
+		{
+			name:         "mark read",
+			call:         func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) { return api.MarkRead(context.TODO(), "wamid.1") },
+			server:       &statusServer{success: true},
+			want:         &whatsappTY.ReadReceipt{MessageID: "wamid.1", Success: true},
+			wantRequests: []map[string]any{readRequest("wamid.1")},
+		},
+		{
+			name:         "mark read not succeeded",
+			call:         func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) { return api.MarkRead(context.TODO(), "wamid.1") },
+			server:       &statusServer{},
+			want:         &whatsappTY.ReadReceipt{MessageID: "wamid.1"},
+			wantRequests: []map[string]any{readRequest("wamid.1")},
+		},
+		{
+			name:         "mark read failed",
+			call:         func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) { return api.MarkRead(context.TODO(), "wamid.1") },
+			server:       &statusServer{fail: true},
+			wantErr:      true,
+			wantRequests: []map[string]any{readRequest("wamid.1")},
+		},
+		{
+			name:    "empty message id",
+			call:    func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) { return api.MarkRead(context.TODO(), "") },
+			server:  &statusServer{success: true},
+			wantErr: true,
+		},
+		{
+			name: "mark read with typing",
+			call: func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) {
+				return api.MarkReadWithTyping(context.TODO(), "wamid.1")
+			},
+			server:       &statusServer{success: true},
+			want:         &whatsappTY.ReadReceipt{MessageID: "wamid.1", Success: true},
+			wantRequests: []map[string]any{withTyping(readRequest("wamid.1"))},
+		},
+		{
+			name: "mark thread read posts the latest message",
+			call: func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) {
+				return api.MarkThreadRead(context.TODO(), "wamid.1", "wamid.2", "wamid.3")
+			},
+			server:       &statusServer{success: true},
+			want:         &whatsappTY.ReadReceipt{MessageID: "wamid.3", Success: true},
+			wantRequests: []map[string]any{readRequest("wamid.3")},
+		},
+		{
+			name:    "mark thread read without messages",
+			call:    func(api *MessageAPI) (*whatsappTY.ReadReceipt, error) { return api.MarkThreadRead(context.TODO()) },
+			server:  &statusServer{success: true},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newTestMessageAPI(t, tc.server)
+
+			got, err := tc.call(api)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("receipt = %+v, want %+v", got, tc.want)
+			}
+			if len(tc.server.requests) != len(tc.wantRequests) {
+				t.Fatalf("requests = %v, want %v", tc.server.requests, tc.wantRequests)
+			}
+			for index, request := range tc.server.requests {
+				if !reflect.DeepEqual(request, tc.wantRequests[index]) {
+					t.Errorf("request[%d] = %v, want %v", index, request, tc.wantRequests[index])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package phonenumber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultFields returned by List and Get, when the fields are not specified
+var DefaultFields = []string{
+	"id", "display_phone_number", "verified_name", "quality_rating", "messaging_limit_tier",
+	"name_status", "throughput", "code_verification_status", "platform_type", "status",
+}
+
+var (
+	pinRegex    = regexp.MustCompile(`^[0-9]{6}$`)
+	regionRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+type PhoneNumberAPI struct {
+	logger            *zap.Logger
+	businessAccountID string
+	client            *customClient.Client
+}
+
+func New(ctx context.Context, client *customClient.Client, businessAccountID string) *PhoneNumberAPI {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	return &PhoneNumberAPI{
+		businessAccountID: businessAccountID,
+		client:            client,
+		logger:            logger.Named("phone_number_api"),
+	}
+}
+
+func validatePIN(pin string) error {
+	if !pinRegex.MatchString(pin) {
+		return &whatsappTY.ValidationError{Field: "pin", Reason: "should be 6 digits"}
+	}
+	return nil
+}
+
+// List returns a page of phone numbers of the business account
+func (pa *PhoneNumberAPI) List(ctx context.Context, options *whatsappTY.PhoneNumberListOptions) (*whatsappTY.PhoneNumberList, error) {
+	if pa.businessAccountID == "" {
+		return nil, errors.New("business account id can not be empty")
+	}
+	// /{{WABA-ID}}/phone_numbers
+	api := fmt.Sprintf("/%s/phone_numbers", pa.businessAccountID)
+	pageOptions := whatsappTY.PhoneNumberListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	if pageOptions.Fields == "" {
+		pageOptions.Fields = strings.Join(DefaultFields, ",")
+	}
+	out := &whatsappTY.PhoneNumberList{}
+	err := pa.client.Get(ctx, api, nil, &pageOptions, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAll returns the phone numbers from all the pages
+func (pa *PhoneNumberAPI) ListAll(ctx context.Context, options *whatsappTY.PhoneNumberListOptions) ([]whatsappTY.PhoneNumber, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, pa.logger)
+	pageOptions := whatsappTY.PhoneNumberListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	phoneNumbers := []whatsappTY.PhoneNumber{}
+	for {
+		page, err := pa.List(ctx, &pageOptions)
+		if err != nil {
+			return nil, err
+		}
+		phoneNumbers = append(phoneNumbers, page.Data...)
+		if !page.Paging.HasNext() || len(page.Data) == 0 {
+			break
+		}
+		pageOptions.After = page.Paging.Cursors.After
+		pageOptions.Before = ""
+		logger.Debug("fetching next page of phone numbers", zap.String("after", pageOptions.After))
+	}
+	return phoneNumbers, nil
+}
+
+// Get returns the phone number with the given fields, DefaultFields used if not specified
+func (pa *PhoneNumberAPI) Get(ctx context.Context, phoneNumberID string, fields ...string) (*whatsappTY.PhoneNumber, error) {
+	if phoneNumberID == "" {
+		return nil, errors.New("phone number id can not be empty")
+	}
+	// /{{Phone-Number-ID}}
+	api := fmt.Sprintf("/%s", phoneNumberID)
+	if len(fields) == 0 {
+		fields = DefaultFields
+	}
+	queryParams := map[string]string{"fields": strings.Join(fields, ",")}
+	out := &whatsappTY.PhoneNumber{}
+	err := pa.client.Get(ctx, api, nil, queryParams, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Register registers the phone number for the cloud api,
+// the pin is the two-step verification pin, set on the first registration. options are optional
+func (pa *PhoneNumberAPI) Register(ctx context.Context, phoneNumberID, pin string, options *whatsappTY.PhoneNumberRegisterOptions) error {
+	if err := validatePIN(pin); err != nil {
+		return err
+	}
+	// /{{Phone-Number-ID}}/register
+	api := fmt.Sprintf("/%s/register", phoneNumberID)
+	body := &whatsappTY.PhoneNumberPINRequest{
+		MessagingProduct: whatsappTY.DEFAULT_MESSAGING_PRODUCT,
+		PIN:              pin,
+	}
+	if options != nil && options.DataLocalizationRegion != "" {
+		if !regionRegex.MatchString(options.DataLocalizationRegion) {
+			return &whatsappTY.ValidationError{Field: "data_localization_region", Reason: "should be ISO 3166 alpha-2 country code"}
+		}
+		body.DataLocalizationRegion = options.DataLocalizationRegion
+	}
+	return pa.post(ctx, api, body, "registering phone number", phoneNumberID)
+}
+
+// Deregister removes the phone number from the cloud api
+func (pa *PhoneNumberAPI) Deregister(ctx context.Context, phoneNumberID string) error {
+	// /{{Phone-Number-ID}}/deregister
+	api := fmt.Sprintf("/%s/deregister", phoneNumberID)
+	return pa.post(ctx, api, nil, "deregistering phone number", phoneNumberID)
+}
+
+// RequestCode sends a verification code to the phone number, with SMS or VOICE method.
+// language of the message, example: en_US
+func (pa *PhoneNumberAPI) RequestCode(ctx context.Context, phoneNumberID, codeMethod, language string) error {
+	if codeMethod != whatsappTY.CODE_METHOD_SMS && codeMethod != whatsappTY.CODE_METHOD_VOICE {
+		return &whatsappTY.ValidationError{Field: "code_method", Reason: fmt.Sprintf("invalid method %s, options: SMS, VOICE", codeMethod)}
+	}
+	if language == "" {
+		return &whatsappTY.ValidationError{Field: "language", Reason: "required"}
+	}
+	// /{{Phone-Number-ID}}/request_code
+	api := fmt.Sprintf("/%s/request_code", phoneNumberID)
+	body := &whatsappTY.PhoneNumberCodeRequest{CodeMethod: codeMethod, Language: language}
+	return pa.post(ctx, api, body, "requesting verification code", phoneNumberID)
+}
+
+// VerifyCode verifies the phone number with the received code
+func (pa *PhoneNumberAPI) VerifyCode(ctx context.Context, phoneNumberID, code string) error {
+	if code == "" {
+		return &whatsappTY.ValidationError{Field: "code", Reason: "required"}
+	}
+	// /{{Phone-Number-ID}}/verify_code
+	api := fmt.Sprintf("/%s/verify_code", phoneNumberID)
+	body := &whatsappTY.PhoneNumberCodeRequest{Code: code}
+	return pa.post(ctx, api, body, "verifying code", phoneNumberID)
+}
+
+// SetTwoStepPIN sets or changes the two-step verification pin of the phone number
+func (pa *PhoneNumberAPI) SetTwoStepPIN(ctx context.Context, phoneNumberID, pin string) error {
+	if err := validatePIN(pin); err != nil {
+		return err
+	}
+	// /{{Phone-Number-ID}}
+	api := fmt.Sprintf("/%s", phoneNumberID)
+	body := &whatsappTY.PhoneNumberPINRequest{PIN: pin}
+	return pa.post(ctx, api, body, "setting two-step verification pin", phoneNumberID)
+}
+
+// posts the request, the api responds with the success status
+func (pa *PhoneNumberAPI) post(ctx context.Context, api string, body any, action, phoneNumberID string) error {
+	if phoneNumberID == "" {
+		return errors.New("phone number id can not be empty")
+	}
+	out := &whatsappTY.StatusResponse{}
+	err := pa.client.Post(ctx, api, nil, nil, body, out)
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return fmt.Errorf("error on %s:%s", action, phoneNumberID)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package phonenumber
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// received request of the test server
+type testRequest struct {
+	method string
+	path   string
+	query  url.Values
+	body   map[string]any
+}
+
+// records the requests and responds with the handler
+type testServer struct {
+	mutex    sync.Mutex
+	requests []testRequest
+	handler  func(w http.ResponseWriter, r *http.Request)
+}
+
+func (ts *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	request := testRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query()}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&request.body)
+	}
+	ts.requests = append(ts.requests, request)
+	if ts.handler != nil {
+		ts.handler(w, r)
+		return
+	}
+	fmt.Fprint(w, `{"success":true}`)
+}
+
+func newTestPhoneNumberAPI(t *testing.T, server *testServer) *PhoneNumberAPI {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+	client, err := customClient.New(ctx, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(ctx, client, "waba-1")
+}
+
+func TestListAll(t *testing.T) {
+	// three pages, the cursor of the page is the index of the next page
+	pages := []string{
+		`{"data":[{"id":"1"},{"id":"2"}],"paging":{"cursors":{"after":"page-1"},"next":"https://graph/next"}}`,
+		`{"data":[{"id":"3"}],"paging":{"cursors":{"before":"page-0","after":"page-2"},"next":"https://graph/next"}}`,
+		`{"data":[{"id":"4"}],"paging":{"cursors":{"before":"page-1","after":"page-3"}}}`,
+	}
+	server := &testServer{handler: func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, pages[0])
+		case "page-1":
+			fmt.Fprint(w, pages[1])
+		case "page-2":
+			fmt.Fprint(w, pages[2])
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"invalid cursor","code":100}}`)
+		}
+	}}
+	api := newTestPhoneNumberAPI(t, server)
+
+	phoneNumbers, err := api.ListAll(context.TODO(), &whatsappTY.PhoneNumberListOptions{Limit: 2, Before: "ignored"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := []string{}
+	for _, phoneNumber := range phoneNumbers {
+		ids = append(ids, phoneNumber.ID)
+	}
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+
+	if len(server.requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(server.requests))
+	}
+	for index, request := range server.requests {
+		if request.method != http.MethodGet || request.path != "/waba-1/phone_numbers" {
+			t.Errorf("request[%d] = %s %s", index, request.method, request.path)
+		}
+		if request.query.Get("limit") != "2" {
+			t.Errorf("request[%d] limit = %q, want 2", index, request.query.Get("limit"))
+		}
+		if request.query.Get("fields") == "" {
+			t.Errorf("request[%d] default fields not set", index)
+		}
+		if index > 0 && request.query.Get("before") != "" {
+			t.Errorf("request[%d] before cursor = %q, want empty", index, request.query.Get("before"))
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name      string
+		pin       string
+		options   *whatsappTY.PhoneNumberRegisterOptions
+		response  string
+		wantBody  map[string]any
+		wantErr   bool
+		wantValid bool // validation error, request not sent
+	}{
+		{
+			name:     "register",
+			pin:      "123456",
+			wantBody: map[string]any{"messaging_product": "whatsapp", "pin": "123456"},
+		},
+		{
+			name:     "register with data localization region",
+			pin:      "123456",
+			options:  &whatsappTY.PhoneNumberRegisterOptions{DataLocalizationRegion: "DE"},
+			wantBody: map[string]any{"messaging_product": "whatsapp", "pin": "123456", "data_localization_region": "DE"},
+		},
+		{name: "invalid pin", pin: "1234", wantErr: true, wantValid: true},
+		{
+			name:      "invalid region",
+			pin:       "123456",
+			options:   &whatsappTY.PhoneNumberRegisterOptions{DataLocalizationRegion: "germany"},
+			wantErr:   true,
+			wantValid: true,
+		},
+		{
+			name:     "not succeeded",
+			pin:      "123456",
+			response: `{"success":false}`,
+			wantBody: map[string]any{"messaging_product": "whatsapp", "pin": "123456"},
+			wantErr:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &testServer{}
+			if tc.response != "" {
+				server.handler = func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, tc.response) }
+			}
+			api := newTestPhoneNumberAPI(t, server)
+
+			err := api.Register(context.TODO(), "phone-1", tc.pin, tc.options)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tc.wantErr)
+			}
+			var validationErr *whatsappTY.ValidationError
+			if errors.As(err, &validationErr) != tc.wantValid {
+				t.Errorf("validation error = %v, want %v", err, tc.wantValid)
+			}
+			if tc.wantValid {
+				if len(server.requests) != 0 {
+					t.Errorf("requests = %d, want 0", len(server.requests))
+				}
+				return
+			}
+			if len(server.requests) != 1 {
+				t.Fatalf("requests = %d, want 1", len(server.requests))
+			}
+			request := server.requests[0]
+			if request.method != http.MethodPost || request.path != "/phone-1/register" {
+				t.Errorf("request = %s %s", request.method, request.path)
+			}
+			if !reflect.DeepEqual(request.body, tc.wantBody) {
+				t.Errorf("body = %v, want %v", request.body, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestDeregister(t *testing.T) {
+	server := &testServer{}
+	api := newTestPhoneNumberAPI(t, server)
+
+	if err := api.Deregister(context.TODO(), "phone-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(server.requests))
+	}
+	if request := server.requests[0]; request.method != http.MethodPost || request.path != "/phone-1/deregister" {
+		t.Errorf("request = %s %s", request.method, request.path)
+	}
+
+	if err := api.Deregister(context.TODO(), ""); err == nil {
+		t.Error("expected error on empty phone number id")
+	}
+}
+
+func TestVerificationCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(api *PhoneNumberAPI) error
+		wantPath string
+		wantBody map[string]any
+		wantErr  bool
+	}{
+		{
+			name:     "request sms code",
+			call:     func(api *PhoneNumberAPI) error { return api.RequestCode(context.TODO(), "phone-1", "SMS", "en_US") },
+			wantPath: "/phone-1/request_code",
+			wantBody: map[string]any{"code_method": "SMS", "language": "en_US"},
+		},
+		{
+			name:     "request voice code",
+			call:     func(api *PhoneNumberAPI) error { return api.RequestCode(context.TODO(), "phone-1", "VOICE", "de") },
+			wantPath: "/phone-1/request_code",
+			wantBody: map[string]any{"code_method": "VOICE", "language": "de"},
+		},
+		{
+			name:    "invalid code method",
+			call:    func(api *PhoneNumberAPI) error { return api.RequestCode(context.TODO(), "phone-1", "EMAIL", "en_US") },
+			wantErr: true,
+		},
+		{
+			name:    "missing language",
+			call:    func(api *PhoneNumberAPI) error { return api.RequestCode(context.TODO(), "phone-1", "SMS", "") },
+			wantErr: true,
+		},
+		{
+			name:     "verify code",
+			call:     func(api *PhoneNumberAPI) error { return api.VerifyCode(context.TODO(), "phone-1", "123456") },
+			wantPath: "/phone-1/verify_code",
+			wantBody: map[string]any{"code": "123456"},
+		},
+		{
+			name:    "missing code",
+			call:    func(api *PhoneNumberAPI) error { return api.VerifyCode(context.TODO(), "phone-1", "") },
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &testServer{}
+			api := newTestPhoneNumberAPI(t, server)
+
+			err := tc.call(api)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				if len(server.requests) != 0 {
+					t.Errorf("requests = %d, want 0", len(server.requests))
+				}
+				return
+			}
+			if len(server.requests) != 1 {
+				t.Fatalf("requests = %d, want 1", len(server.requests))
+			}
+			request := server.requests[0]
+			if request.method != http.MethodPost || request.path != tc.wantPath {
+				t.Errorf("request = %s %s, want POST %s", request.method, request.path, tc.wantPath)
+			}
+			if !reflect.DeepEqual(request.body, tc.wantBody) {
+				t.Errorf("body = %v, want %v", request.body, tc.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	messageAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/message"
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// ErrUnknownAlias returned when the phone number alias is not available in the registry
+var ErrUnknownAlias = errors.New("unknown phone number alias")
+
+// calling code of the recipients served by the phone number
+type countryRoute struct {
+	code  string
+	alias string
+}
+
+// RegistryOptions are applied to every client of the registry
+type RegistryOptions struct {
+	Limiter  *customClient.Limiter // shared by all the phone numbers, default: from the whatsapp config rate limit, if enabled
+	Guard    messageAPI.Guard      // message guard, example: customer service window guard
+	Recorder messageAPI.Recorder   // message recorder, example: delivery status ledger
+}
+
+// Registry keeps the clients of multiple business accounts and phone numbers.
+// clients are created on the first use and share the http transport, rate limiter and media cache
+type Registry struct {
+	ctx          context.Context
+	logger       *zap.Logger
+	configs      map[string]types.WhatsAppConfig // key: alias
+	aliases      []string
+	defaultAlias string
+	routes       []countryRoute // longest calling code first
+	shared       *sharedResources
+	guard        messageAPI.Guard
+	recorder     messageAPI.Recorder
+	mutex        sync.Mutex
+	clients      map[string]*WhatsAppClient
+}
+
+// NewRegistry returns a registry of the phone numbers from the accounts.
+// the whatsapp config is used as defaults (version, retry, rate limit, credentials) of the accounts,
+// if no accounts defined, the phone number of the whatsapp config is registered
+func NewRegistry(ctx context.Context, cfg types.WhatsAppConfig, accounts []types.AccountConfig, options RegistryOptions) (*Registry, error) {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{
+		ctx:      ctx,
+		logger:   logger.Named("whatsapp_registry"),
+		configs:  map[string]types.WhatsAppConfig{},
+		shared:   &sharedResources{httpClient: &http.Client{}, limiter: options.Limiter},
+		guard:    options.Guard,
+		recorder: options.Recorder,
+		clients:  map[string]*WhatsAppClient{},
+	}
+	// created upfront, not from the config of the first client
+	if registry.shared.limiter == nil && cfg.RateLimit.Enabled {
+		registry.shared.limiter = customClient.NewLimiter(cfg.RateLimit)
+	}
+
+	if len(accounts) == 0 {
+		accounts = []types.AccountConfig{{PhoneNumbers: []types.PhoneNumberConfig{{PhoneNumberID: cfg.PhoneNumberID, Default: true}}}}
+	}
+
+	for _, account := range accounts {
+		for _, phoneNumber := range account.PhoneNumbers {
+			err := registry.add(cfg, account, phoneNumber)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(registry.aliases) == 0 {
+		return nil, errors.New("no phone numbers configured")
+	}
+	if registry.defaultAlias == "" {
+		registry.defaultAlias = registry.aliases[0]
+	}
+
+	sort.SliceStable(registry.routes, func(i, j int) bool {
+		return len(registry.routes[i].code) > len(registry.routes[j].code)
+	})
+
+	return registry, nil
+}
+
+// adds the phone number, account fields override the defaults
+func (r *Registry) add(defaults types.WhatsAppConfig, account types.AccountConfig, phoneNumber types.PhoneNumberConfig) error {
+	if phoneNumber.PhoneNumberID == "" {
+		return fmt.Errorf("phone number id can not be empty, account:%s", account.Name)
+	}
+	alias := phoneNumber.Alias
+	if alias == "" {
+		alias = phoneNumber.PhoneNumberID
+	}
+	if _, found := r.configs[alias]; found {
+		return fmt.Errorf("duplicate phone number alias:%s", alias)
+	}
+
+	cfg := defaults
+	cfg.PhoneNumberID = phoneNumber.PhoneNumberID
+	if account.AppID != "" {
+		cfg.AppID = account.AppID
+	}
+	if account.BusinessAccountID != "" {
+		cfg.BusinessAccountID = account.BusinessAccountID
+	}
+	if account.AccessToken != "" {
+		cfg.AccessToken = account.AccessToken
+	}
+
+	r.configs[alias] = cfg
+	r.aliases = append(r.aliases, alias)
+	if phoneNumber.Default && r.defaultAlias == "" {
+		r.defaultAlias = alias
+	}
+	for _, code := range phoneNumber.CountryCodes {
+		code = customClient.NormalizePhoneNumber(code)
+		if code == "" {
+			return fmt.Errorf("invalid country code, alias:%s", alias)
+		}
+		for _, route := range r.routes {
+			if route.code == code {
+				return fmt.Errorf("country code %s assigned to %s and %s", code, route.alias, alias)
+			}
+		}
+		r.routes = append(r.routes, countryRoute{code: code, alias: alias})
+	}
+	return nil
+}
+
+// Aliases returns the aliases of the registered phone numbers, in the configured order
+func (r *Registry) Aliases() []string {
+	return append([]string{}, r.aliases...)
+}
+
+// DefaultAlias returns the alias of the default phone number
+func (r *Registry) DefaultAlias() string {
+	return r.defaultAlias
+}
+
+// Client returns the client of the phone number alias, empty alias returns the default client
+func (r *Registry) Client(alias string) (*WhatsAppClient, error) {
+	if alias == "" {
+		alias = r.defaultAlias
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if client, found := r.clients[alias]; found {
+		return client, nil
+	}
+
+	cfg, found := r.configs[alias]
+	if !found {
+		return nil, fmt.Errorf("%w:%s", ErrUnknownAlias, alias)
+	}
+	client, err := newClient(r.ctx, cfg, r.shared)
+	if err != nil {
+		return nil, err
+	}
+	client.SetMessageGuard(r.guard)
+	client.SetMessageRecorder(r.recorder)
+	r.logger.Debug("client created", zap.String("alias", alias), zap.String("phoneNumberId", cfg.PhoneNumberID))
+	r.clients[alias] = client
+	return client, nil
+}
+
+// AliasFor returns the alias of the phone number serving the recipient country code,
+// the default alias is returned if no country code matches
+func (r *Registry) AliasFor(recipient string) string {
+	recipient = customClient.NormalizePhoneNumber(recipient)
+	for _, route := range r.routes {
+		if strings.HasPrefix(recipient, route.code) {
+			return route.alias
+		}
+	}
+	return r.defaultAlias
+}
+
+// ClientFor returns the client of the phone number serving the recipient
+func (r *Registry) ClientFor(recipient string) (*WhatsAppClient, error) {
+	return r.Client(r.AliasFor(recipient))
+}
+
+// SendFrom sends the message from the phone number alias
+func (r *Registry) SendFrom(ctx context.Context, alias string, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+	client, err := r.Client(alias)
+	if err != nil {
+		return nil, err
+	}
+	return client.Message().Post(ctx, message)
+}
+
+// Send sends the message from the phone number chosen by the recipient country code
+func (r *Registry) Send(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+	return r.SendFrom(ctx, r.AliasFor(message.To), message)
+}
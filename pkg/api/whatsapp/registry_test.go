@@ -0,0 +1,147 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+var errRejected = errors.New("rejected by test guard")
+
+// rejects every message, records the phone number ids
+type testGuard struct {
+	phoneNumberIDs []string
+}
+
+func (tg *testGuard) Check(ctx context.Context, phoneNumberID string, message whatsappTY.Message) (whatsappTY.Message, error) {
+	tg.phoneNumberIDs = append(tg.phoneNumberIDs, phoneNumberID)
+	return message, errRejected
+}
+
+type testRecorder struct{}
+
+func (tr *testRecorder) RecordSent(ctx context.Context, phoneNumberID string, message whatsappTY.Message, response *whatsappTY.MessageResponse) {
+}
+
+func testAccounts() []types.AccountConfig {
+	return []types.AccountConfig{
+		{Name: "india", AccessToken: "token-in", PhoneNumbers: []types.PhoneNumberConfig{
+			{Alias: "in", PhoneNumberID: "111", CountryCodes: []string{"91"}},
+		}},
+		{Name: "north america", PhoneNumbers: []types.PhoneNumberConfig{
+			{Alias: "us", PhoneNumberID: "222", CountryCodes: []string{"1"}, Default: true},
+			{Alias: "ca", PhoneNumberID: "333", CountryCodes: []string{"1 204"}},
+		}},
+	}
+}
+
+func newTestRegistry(t *testing.T, cfg types.WhatsAppConfig, options RegistryOptions) *Registry {
+	t.Helper()
+	ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+	registry, err := NewRegistry(ctx, cfg, testAccounts(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestRegistryAliasFor(t *testing.T) {
+	registry := newTestRegistry(t, types.WhatsAppConfig{}, RegistryOptions{})
+
+	tests := []struct {
+		recipient string
+		want      string
+	}{
+		{recipient: "+91 98765 43210", want: "in"},
+		{recipient: "12025550123", want: "us"},
+		{recipient: "1-204-555-0123", want: "ca"},
+		{recipient: "447700900123", want: "us"}, // default
+	}
+	for _, tc := range tests {
+		t.Run(tc.recipient, func(t *testing.T) {
+			if got := registry.AliasFor(tc.recipient); got != tc.want {
+				t.Errorf("alias = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts []types.AccountConfig
+	}{
+		{name: "empty phone number id", accounts: []types.AccountConfig{{PhoneNumbers: []types.PhoneNumberConfig{{Alias: "a"}}}}},
+		{name: "duplicate alias", accounts: []types.AccountConfig{{PhoneNumbers: []types.PhoneNumberConfig{{Alias: "a", PhoneNumberID: "1"}, {Alias: "a", PhoneNumberID: "2"}}}}},
+		{name: "duplicate country code", accounts: []types.AccountConfig{{PhoneNumbers: []types.PhoneNumberConfig{{PhoneNumberID: "1", CountryCodes: []string{"91"}}, {PhoneNumberID: "2", CountryCodes: []string{"+91"}}}}}},
+		{name: "invalid country code", accounts: []types.AccountConfig{{PhoneNumbers: []types.PhoneNumberConfig{{PhoneNumberID: "1", CountryCodes: []string{"+"}}}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+			if _, err := NewRegistry(ctx, types.WhatsAppConfig{}, tc.accounts, RegistryOptions{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRegistryOptions(t *testing.T) {
+	limiter := customClient.NewLimiter(types.RateLimitConfig{})
+
+	tests := []struct {
+		name        string
+		cfg         types.WhatsAppConfig
+		options     RegistryOptions
+		wantLimiter bool
+	}{
+		{name: "no rate limit", wantLimiter: false},
+		{name: "rate limit from config", cfg: types.WhatsAppConfig{RateLimit: types.RateLimitConfig{Enabled: true}}, wantLimiter: true},
+		{name: "limiter from options", options: RegistryOptions{Limiter: limiter}, wantLimiter: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			guard := &testGuard{}
+			tc.options.Guard = guard
+			tc.options.Recorder = &testRecorder{}
+			registry := newTestRegistry(t, tc.cfg, tc.options)
+
+			var shared *customClient.Limiter
+			for index, alias := range registry.Aliases() {
+				client, err := registry.Client(alias)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if client.messageGuard != guard || client.messageRecorder != tc.options.Recorder {
+					t.Errorf("%s: guard or recorder not applied", alias)
+				}
+				got := client.client.Limiter()
+				if (got != nil) != tc.wantLimiter {
+					t.Fatalf("%s: limiter = %v, want limiter %v", alias, got, tc.wantLimiter)
+				}
+				if tc.options.Limiter != nil && got != tc.options.Limiter {
+					t.Errorf("%s: limiter from options not used", alias)
+				}
+				if index > 0 && got != shared {
+					t.Errorf("%s: limiter not shared", alias)
+				}
+				shared = got
+			}
+
+			// guard rejects before posting
+			_, err := registry.Send(context.TODO(), whatsappTY.Message{To: "919876543210", Type: whatsappTY.MESSAGE_TYPE_TEXT, Text: &whatsappTY.MessageTextObject{Body: "hi"}})
+			if !errors.Is(err, errRejected) {
+				t.Fatalf("error = %v, want %v", err, errRejected)
+			}
+			if len(guard.phoneNumberIDs) != 1 || guard.phoneNumberIDs[0] != "111" {
+				t.Errorf("guard called with %v, want [111]", guard.phoneNumberIDs)
+			}
+		})
+	}
+}
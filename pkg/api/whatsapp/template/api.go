@@ -0,0 +1,177 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+type TemplateAPI struct {
+	logger            *zap.Logger
+	businessAccountID string
+	client            *customClient.Client
+}
+
+func New(ctx context.Context, client *customClient.Client, businessAccountID string) *TemplateAPI {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	return &TemplateAPI{
+		businessAccountID: businessAccountID,
+		client:            client,
+		logger:            logger.Named("template_api"),
+	}
+}
+
+func (ta *TemplateAPI) templatesAPI() (string, error) {
+	if ta.businessAccountID == "" {
+		return "", errors.New("business account id can not be empty")
+	}
+	// /{{WABA-ID}}/message_templates
+	return fmt.Sprintf("/%s/message_templates", ta.businessAccountID), nil
+}
+
+// List returns a page of templates, filtered by the options
+func (ta *TemplateAPI) List(ctx context.Context, options *whatsappTY.TemplateListOptions) (*whatsappTY.TemplateList, error) {
+	api, err := ta.templatesAPI()
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &whatsappTY.TemplateListOptions{}
+	}
+	out := &whatsappTY.TemplateList{}
+	err = ta.client.Get(ctx, api, nil, options, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAll returns the templates from all the pages, filtered by the options
+func (ta *TemplateAPI) ListAll(ctx context.Context, options *whatsappTY.TemplateListOptions) ([]whatsappTY.Template, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, ta.logger)
+	pageOptions := whatsappTY.TemplateListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	templates := []whatsappTY.Template{}
+	for {
+		page, err := ta.List(ctx, &pageOptions)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, page.Data...)
+		if !page.Paging.HasNext() || len(page.Data) == 0 {
+			break
+		}
+		pageOptions.After = page.Paging.Cursors.After
+		pageOptions.Before = ""
+		logger.Debug("fetching next page of templates", zap.String("after", pageOptions.After))
+	}
+	return templates, nil
+}
+
+// Get returns the template by id
+func (ta *TemplateAPI) Get(ctx context.Context, templateID string) (*whatsappTY.Template, error) {
+	// /{{Template-ID}}
+	api := fmt.Sprintf("/%s", templateID)
+	out := &whatsappTY.Template{}
+	err := ta.client.Get(ctx, api, nil, nil, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create submits a new template for review
+func (ta *TemplateAPI) Create(ctx context.Context, template *whatsappTY.Template) (*whatsappTY.TemplateCreateResponse, error) {
+	api, err := ta.templatesAPI()
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, errors.New("template can not be nil")
+	}
+
+	// id and status are assigned by the api
+	body := *template
+	body.ID = ""
+	body.Status = ""
+	body.RejectedReason = ""
+	body.QualityScore = nil
+
+	out := &whatsappTY.TemplateCreateResponse{}
+	err = ta.client.Post(ctx, api, nil, nil, &body, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Edit updates the category and components of the template.
+// name and language of a template can not be changed
+func (ta *TemplateAPI) Edit(ctx context.Context, templateID string, template *whatsappTY.Template) error {
+	if template == nil {
+		return errors.New("template can not be nil")
+	}
+	// /{{Template-ID}}
+	api := fmt.Sprintf("/%s", templateID)
+	body := whatsappTY.Template{
+		Category:              template.Category,
+		Components:            template.Components,
+		MessageSendTTLSeconds: template.MessageSendTTLSeconds,
+	}
+	out := &whatsappTY.StatusResponse{}
+	err := ta.client.Post(ctx, api, nil, nil, &body, out)
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return fmt.Errorf("error on editing template:%s", templateID)
+	}
+	return nil
+}
+
+// DeleteByName deletes the template in all the languages
+func (ta *TemplateAPI) DeleteByName(ctx context.Context, name string) error {
+	return ta.delete(ctx, name, "")
+}
+
+// DeleteByID deletes the template of a single language, identified by the hsm id (template id)
+func (ta *TemplateAPI) DeleteByID(ctx context.Context, name, hsmID string) error {
+	if hsmID == "" {
+		return errors.New("hsm id can not be empty")
+	}
+	return ta.delete(ctx, name, hsmID)
+}
+
+func (ta *TemplateAPI) delete(ctx context.Context, name, hsmID string) error {
+	api, err := ta.templatesAPI()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("template name can not be empty")
+	}
+	queryParams := map[string]string{"name": name}
+	if hsmID != "" {
+		queryParams["hsm_id"] = hsmID
+	}
+	out := &whatsappTY.StatusResponse{}
+	err = ta.client.Delete(ctx, api, nil, queryParams, out)
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return fmt.Errorf("error on deleting template:%s", name)
+	}
+	return nil
+}
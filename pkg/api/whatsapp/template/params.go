@@ -0,0 +1,311 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// Placeholders returns the distinct placeholder names of the text in the order of appearance.
+// positional placeholders are returned as "1", "2", ...
+func Placeholders(text string) []string {
+	names := []string{}
+	found := map[string]bool{}
+	for _, match := range placeholderRegex.FindAllStringSubmatch(text, -1) {
+		if !found[match[1]] {
+			found[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+func isNamedFormat(definition *whatsappTY.Template) bool {
+	return strings.EqualFold(definition.ParameterFormat, "NAMED")
+}
+
+// ValidateParameters verifies the components and parameters of the template send against the definition.
+// returns all the violations joined as a single error
+func ValidateParameters(definition *whatsappTY.Template, send *whatsappTY.MessageTemplateObject) error {
+	errs := []error{}
+	addErr := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if definition.Status != "" && !strings.EqualFold(definition.Status, whatsappTY.TEMPLATE_STATUS_APPROVED) {
+		addErr("template %s is not approved, status: %s", definition.Name, definition.Status)
+	}
+
+	var sendHeader, sendBody *whatsappTY.ComponentObject
+	sendButtons := map[int]*whatsappTY.ComponentObject{}
+	for index := range send.Components {
+		component := &send.Components[index]
+		switch strings.ToLower(component.Type) {
+		case "header":
+			sendHeader = component
+		case "body":
+			sendBody = component
+		case "button":
+			buttonIndex, err := strconv.Atoi(component.Index)
+			if err != nil {
+				addErr("button component index %q is not a number", component.Index)
+				continue
+			}
+			if _, found := sendButtons[buttonIndex]; found {
+				addErr("button component index %d repeated", buttonIndex)
+			}
+			sendButtons[buttonIndex] = component
+		default:
+			addErr("unsupported component type %q", component.Type)
+		}
+	}
+
+	// header
+	header := findComponent(definition, whatsappTY.TEMPLATE_COMPONENT_HEADER)
+	switch {
+	case header == nil:
+		if sendHeader != nil {
+			addErr("header: template has no header, but parameters received")
+		}
+	case header.Format == "" || strings.EqualFold(header.Format, whatsappTY.TEMPLATE_FORMAT_TEXT):
+		errs = append(errs, validateTextParameters("header", definition, header.Text, sendHeader)...)
+	default:
+		// media or location header expects exactly one parameter of the format type
+		expectedType := strings.ToLower(header.Format)
+		if sendHeader == nil || len(sendHeader.Parameters) != 1 {
+			addErr("header: expected one %s parameter", expectedType)
+		} else if sendHeader.Parameters[0].Type != expectedType {
+			addErr("header: expected %s parameter, received %s", expectedType, sendHeader.Parameters[0].Type)
+		}
+	}
+
+	// body
+	body := findComponent(definition, whatsappTY.TEMPLATE_COMPONENT_BODY)
+	if body == nil {
+		if sendBody != nil {
+			addErr("body: template has no body, but parameters received")
+		}
+	} else {
+		errs = append(errs, validateTextParameters("body", definition, body.Text, sendBody)...)
+	}
+
+	// buttons
+	buttons := definitionButtons(definition)
+	for buttonIndex, button := range buttons {
+		sendButton := sendButtons[buttonIndex]
+		delete(sendButtons, buttonIndex)
+		errs = append(errs, validateButton(buttonIndex, button, sendButton)...)
+	}
+	remaining := []int{}
+	for buttonIndex := range sendButtons {
+		remaining = append(remaining, buttonIndex)
+	}
+	sort.Ints(remaining)
+	for _, buttonIndex := range remaining {
+		addErr("button[%d]: template has %d buttons, index out of range", buttonIndex, len(buttons))
+	}
+
+	return errors.Join(errs...)
+}
+
+// verifies the parameters of header or body text
+func validateTextParameters(name string, definition *whatsappTY.Template, text string, send *whatsappTY.ComponentObject) []error {
+	errs := []error{}
+	placeholders := Placeholders(text)
+	params := []whatsappTY.ParameterObject{}
+	if send != nil {
+		params = send.Parameters
+	}
+
+	if len(placeholders) != len(params) {
+		errs = append(errs, fmt.Errorf("%s: expected %d parameters, received %d", name, len(placeholders), len(params)))
+	}
+
+	for index, param := range params {
+		switch param.Type {
+		case "text", "currency", "date_time":
+		default:
+			errs = append(errs, fmt.Errorf("%s: parameter[%d] type %q not allowed, expected text, currency or date_time", name, index, param.Type))
+		}
+	}
+
+	if isNamedFormat(definition) {
+		received := map[string]bool{}
+		for index, param := range params {
+			if param.ParameterName == "" {
+				errs = append(errs, fmt.Errorf("%s: parameter[%d] parameter_name required for named template", name, index))
+				continue
+			}
+			received[param.ParameterName] = true
+		}
+		expected := map[string]bool{}
+		for _, placeholder := range placeholders {
+			expected[placeholder] = true
+			if !received[placeholder] {
+				errs = append(errs, fmt.Errorf("%s: named parameter %q missing", name, placeholder))
+			}
+		}
+		for paramName := range received {
+			if !expected[paramName] {
+				errs = append(errs, fmt.Errorf("%s: named parameter %q not defined in the template", name, paramName))
+			}
+		}
+	}
+	return errs
+}
+
+// verifies the parameter of a button
+func validateButton(index int, button whatsappTY.TemplateButton, send *whatsappTY.ComponentObject) []error {
+	errs := []error{}
+	name := fmt.Sprintf("button[%d]", index)
+
+	expectParameter := func(subType, paramType string, required bool) {
+		if send == nil {
+			if required {
+				errs = append(errs, fmt.Errorf("%s: %s button requires the %s parameter", name, strings.ToLower(button.Type), paramType))
+			}
+			return
+		}
+		if !strings.EqualFold(send.SubType, subType) {
+			errs = append(errs, fmt.Errorf("%s: expected sub_type %s, received %q", name, subType, send.SubType))
+		}
+		if len(send.Parameters) != 1 {
+			errs = append(errs, fmt.Errorf("%s: expected one parameter, received %d", name, len(send.Parameters)))
+		} else if send.Parameters[0].Type != paramType {
+			errs = append(errs, fmt.Errorf("%s: expected %s parameter, received %q", name, paramType, send.Parameters[0].Type))
+		}
+	}
+
+	switch strings.ToUpper(button.Type) {
+	case whatsappTY.TEMPLATE_BUTTON_URL:
+		// dynamic url only
+		expectParameter("url", "text", len(Placeholders(button.URL)) > 0)
+	case whatsappTY.TEMPLATE_BUTTON_OTP:
+		expectParameter("url", "text", true)
+	case whatsappTY.TEMPLATE_BUTTON_QUICK_REPLY:
+		expectParameter("quick_reply", "payload", false)
+	case whatsappTY.TEMPLATE_BUTTON_COPY_CODE:
+		expectParameter("copy_code", "coupon_code", true)
+	case whatsappTY.TEMPLATE_BUTTON_FLOW:
+		expectParameter("flow", "action", false)
+	case whatsappTY.TEMPLATE_BUTTON_CATALOG:
+		// optional thumbnail product
+		expectParameter("catalog", "action", false)
+	case whatsappTY.TEMPLATE_BUTTON_MPM:
+		// thumbnail product and the product sections
+		expectParameter("mpm", "action", true)
+	default:
+		if send != nil && len(send.Parameters) > 0 {
+			errs = append(errs, fmt.Errorf("%s: %s button does not accept parameters", name, strings.ToLower(button.Type)))
+		}
+	}
+	return errs
+}
+
+// Render returns a human readable preview of the template send, used for logs and QA
+func Render(definition *whatsappTY.Template, send *whatsappTY.MessageTemplateObject) (string, error) {
+	var sendHeader, sendBody *whatsappTY.ComponentObject
+	for index := range send.Components {
+		switch strings.ToLower(send.Components[index].Type) {
+		case "header":
+			sendHeader = &send.Components[index]
+		case "body":
+			sendBody = &send.Components[index]
+		}
+	}
+
+	sections := []string{}
+	if header := findComponent(definition, whatsappTY.TEMPLATE_COMPONENT_HEADER); header != nil {
+		if header.Format == "" || strings.EqualFold(header.Format, whatsappTY.TEMPLATE_FORMAT_TEXT) {
+			sections = append(sections, substitute(definition, header.Text, sendHeader))
+		} else {
+			media := ""
+			if sendHeader != nil && len(sendHeader.Parameters) > 0 {
+				media = parameterValue(sendHeader.Parameters[0])
+			}
+			sections = append(sections, fmt.Sprintf("[%s: %s]", strings.ToLower(header.Format), media))
+		}
+	}
+	if body := findComponent(definition, whatsappTY.TEMPLATE_COMPONENT_BODY); body != nil {
+		sections = append(sections, substitute(definition, body.Text, sendBody))
+	}
+	if footer := findComponent(definition, whatsappTY.TEMPLATE_COMPONENT_FOOTER); footer != nil && footer.Text != "" {
+		sections = append(sections, footer.Text)
+	}
+
+	buttons := []string{}
+	for _, button := range definitionButtons(definition) {
+		buttons = append(buttons, fmt.Sprintf("[%s]", button.Text))
+	}
+	if len(buttons) > 0 {
+		sections = append(sections, strings.Join(buttons, " "))
+	}
+
+	return strings.Join(sections, "\n\n"), ValidateParameters(definition, send)
+}
+
+// replaces the placeholders with the parameter values
+func substitute(definition *whatsappTY.Template, text string, send *whatsappTY.ComponentObject) string {
+	params := []whatsappTY.ParameterObject{}
+	if send != nil {
+		params = send.Parameters
+	}
+	named := isNamedFormat(definition)
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		if named {
+			for _, param := range params {
+				if param.ParameterName == name {
+					return parameterValue(param)
+				}
+			}
+			return placeholder
+		}
+		position, err := strconv.Atoi(name)
+		if err != nil || position < 1 || position > len(params) {
+			return placeholder
+		}
+		return parameterValue(params[position-1])
+	})
+}
+
+func parameterValue(param whatsappTY.ParameterObject) string {
+	switch param.Type {
+	case "text":
+		return param.Text
+	case "currency":
+		if param.Currency != nil {
+			return param.Currency.FallbackValue
+		}
+	case "date_time":
+		if param.DateTime != nil {
+			return param.DateTime.FallbackValue
+		}
+	case "image", "document", "video":
+		media := param.Image
+		if param.Document != nil {
+			media = param.Document
+		} else if param.Video != nil {
+			media = param.Video
+		}
+		if media != nil {
+			if media.ID != "" {
+				return media.ID
+			}
+			return media.Filename
+		}
+	case "location":
+		if param.Location != nil {
+			return fmt.Sprintf("%s (%f, %f)", param.Location.Name, param.Location.Latitude, param.Location.Longitude)
+		}
+	}
+	return ""
+}
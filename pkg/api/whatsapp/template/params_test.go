@@ -0,0 +1,261 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+func orderTemplate() *whatsappTY.Template {
+	return &whatsappTY.Template{
+		Name:     "order_update",
+		Language: "en_US",
+		Status:   whatsappTY.TEMPLATE_STATUS_APPROVED,
+		Components: []whatsappTY.TemplateComponent{
+			{Type: whatsappTY.TEMPLATE_COMPONENT_HEADER, Format: whatsappTY.TEMPLATE_FORMAT_TEXT, Text: "Order {{1}}"},
+			{Type: whatsappTY.TEMPLATE_COMPONENT_BODY, Text: "Hi {{1}}, your order {{2}} total {{3}} ships on {{4}}."},
+			{Type: whatsappTY.TEMPLATE_COMPONENT_FOOTER, Text: "Thanks"},
+			{Type: whatsappTY.TEMPLATE_COMPONENT_BUTTONS, Buttons: []whatsappTY.TemplateButton{
+				{Type: whatsappTY.TEMPLATE_BUTTON_URL, Text: "Track", URL: "https://example.com/track/{{1}}"},
+				{Type: whatsappTY.TEMPLATE_BUTTON_QUICK_REPLY, Text: "Stop"},
+			}},
+		},
+	}
+}
+
+func orderSend() *whatsappTY.MessageTemplateObject {
+	return &whatsappTY.MessageTemplateObject{
+		Name:     "order_update",
+		Language: whatsappTY.Language{Code: "en_US"},
+		Components: []whatsappTY.ComponentObject{
+			{Type: "header", Parameters: []whatsappTY.ParameterObject{{Type: "text", Text: "#42"}}},
+			{Type: "body", Parameters: []whatsappTY.ParameterObject{
+				{Type: "text", Text: "Alice"},
+				{Type: "text", Text: "#42"},
+				{Type: "currency", Currency: &whatsappTY.CurrencyObject{FallbackValue: "$10.00", Code: "USD", Amount1000: "10000"}},
+				{Type: "date_time", DateTime: &whatsappTY.DateTimeObject{FallbackValue: "Monday"}},
+			}},
+			{Type: "button", SubType: "url", Index: "0", Parameters: []whatsappTY.ParameterObject{{Type: "text", Text: "42"}}},
+		},
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "Hi {{1}}, order {{2}} for {{1}}", want: []string{"1", "2"}},
+		{text: "Hi {{ name }}, order {{order_id}}", want: []string{"name", "order_id"}},
+		{text: "no placeholders", want: []string{}},
+		{text: "broken {{1} and {{}}", want: []string{}},
+	}
+	for _, tc := range tests {
+		if got := Placeholders(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Placeholders(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition func(d *whatsappTY.Template)
+		send       func(s *whatsappTY.MessageTemplateObject)
+		wantErrs   []string // substrings of the expected error
+	}{
+		{name: "valid"},
+		{
+			name:       "not approved",
+			definition: func(d *whatsappTY.Template) { d.Status = whatsappTY.TEMPLATE_STATUS_PAUSED },
+			wantErrs:   []string{"is not approved, status: PAUSED"},
+		},
+		{
+			name:     "missing body parameter",
+			send:     func(s *whatsappTY.MessageTemplateObject) { s.Components[1].Parameters = s.Components[1].Parameters[:3] },
+			wantErrs: []string{"body: expected 4 parameters, received 3"},
+		},
+		{
+			name: "media parameter in body",
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components[1].Parameters[0] = whatsappTY.ParameterObject{Type: "image", Image: &whatsappTY.Media{ID: "1"}}
+			},
+			wantErrs: []string{`body: parameter[0] type "image" not allowed`},
+		},
+		{
+			name:     "missing header",
+			send:     func(s *whatsappTY.MessageTemplateObject) { s.Components = s.Components[1:] },
+			wantErrs: []string{"header: expected 1 parameters, received 0"},
+		},
+		{
+			name:     "missing dynamic url button",
+			send:     func(s *whatsappTY.MessageTemplateObject) { s.Components = s.Components[:2] },
+			wantErrs: []string{"button[0]: url button requires the text parameter"},
+		},
+		{
+			name: "button index out of range",
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components = append(s.Components, whatsappTY.ComponentObject{Type: "button", SubType: "quick_reply", Index: "5"})
+			},
+			wantErrs: []string{"button[5]: template has 2 buttons, index out of range"},
+		},
+		{
+			name: "wrong quick reply parameter",
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components = append(s.Components, whatsappTY.ComponentObject{Type: "button", SubType: "quick_reply", Index: "1",
+					Parameters: []whatsappTY.ParameterObject{{Type: "text", Text: "stop"}}})
+			},
+			wantErrs: []string{`button[1]: expected payload parameter, received "text"`},
+		},
+		{
+			name: "catalog button without parameter",
+			definition: func(d *whatsappTY.Template) {
+				d.Components[3].Buttons = append(d.Components[3].Buttons, whatsappTY.TemplateButton{Type: whatsappTY.TEMPLATE_BUTTON_CATALOG, Text: "View catalog"})
+			},
+		},
+		{
+			name: "catalog button with thumbnail",
+			definition: func(d *whatsappTY.Template) {
+				d.Components[3].Buttons = append(d.Components[3].Buttons, whatsappTY.TemplateButton{Type: whatsappTY.TEMPLATE_BUTTON_CATALOG, Text: "View catalog"})
+			},
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components = append(s.Components, whatsappTY.ComponentObject{Type: "button", SubType: "catalog", Index: "2",
+					Parameters: []whatsappTY.ParameterObject{{Type: "action", Action: &whatsappTY.ParameterActionObject{ThumbnailProductRetailerID: "sku-1"}}}})
+			},
+		},
+		{
+			name: "mpm button",
+			definition: func(d *whatsappTY.Template) {
+				d.Components[3].Buttons = append(d.Components[3].Buttons, whatsappTY.TemplateButton{Type: whatsappTY.TEMPLATE_BUTTON_MPM, Text: "View items"})
+			},
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components = append(s.Components, whatsappTY.ComponentObject{Type: "button", SubType: "mpm", Index: "2",
+					Parameters: []whatsappTY.ParameterObject{{Type: "action", Action: &whatsappTY.ParameterActionObject{
+						ThumbnailProductRetailerID: "sku-1",
+						Sections:                   []whatsappTY.SectionObject{{Title: "Popular", ProductItems: []whatsappTY.SectionProductRow{{ProductRetailerID: "sku-1"}}}},
+					}}}})
+			},
+		},
+		{
+			name: "mpm button without parameter",
+			definition: func(d *whatsappTY.Template) {
+				d.Components[3].Buttons = append(d.Components[3].Buttons, whatsappTY.TemplateButton{Type: whatsappTY.TEMPLATE_BUTTON_MPM, Text: "View items"})
+			},
+			wantErrs: []string{"button[2]: mpm button requires the action parameter"},
+		},
+		{
+			name: "image header",
+			definition: func(d *whatsappTY.Template) {
+				d.Components[0] = whatsappTY.TemplateComponent{Type: whatsappTY.TEMPLATE_COMPONENT_HEADER, Format: whatsappTY.TEMPLATE_FORMAT_IMAGE}
+			},
+			wantErrs: []string{"header: expected image parameter, received text"},
+		},
+		{
+			name: "named parameters",
+			definition: func(d *whatsappTY.Template) {
+				d.ParameterFormat = "NAMED"
+				d.Components = []whatsappTY.TemplateComponent{{Type: whatsappTY.TEMPLATE_COMPONENT_BODY, Text: "Hi {{name}}, order {{order_id}}"}}
+			},
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components = []whatsappTY.ComponentObject{{Type: "body", Parameters: []whatsappTY.ParameterObject{
+					{Type: "text", ParameterName: "name", Text: "Alice"},
+					{Type: "text", ParameterName: "order", Text: "#42"},
+				}}}
+			},
+			wantErrs: []string{`named parameter "order_id" missing`, `named parameter "order" not defined`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			definition, send := orderTemplate(), orderSend()
+			if tc.definition != nil {
+				tc.definition(definition)
+			}
+			if tc.send != nil {
+				tc.send(send)
+			}
+
+			err := ValidateParameters(definition, send)
+			if len(tc.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v", tc.wantErrs)
+			}
+			for _, want := range tc.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition func(d *whatsappTY.Template)
+		send       func(s *whatsappTY.MessageTemplateObject)
+		want       string
+		wantErr    bool
+	}{
+		{
+			name: "positional",
+			want: "Order #42\n\nHi Alice, your order #42 total $10.00 ships on Monday.\n\nThanks\n\n[Track] [Stop]",
+		},
+		{
+			name:    "missing parameters kept as placeholders",
+			send:    func(s *whatsappTY.MessageTemplateObject) { s.Components[1].Parameters = s.Components[1].Parameters[:2] },
+			want:    "Order #42\n\nHi Alice, your order #42 total {{3}} ships on {{4}}.\n\nThanks\n\n[Track] [Stop]",
+			wantErr: true,
+		},
+		{
+			name: "named",
+			definition: func(d *whatsappTY.Template) {
+				d.ParameterFormat = "NAMED"
+				d.Components = []whatsappTY.TemplateComponent{{Type: whatsappTY.TEMPLATE_COMPONENT_BODY, Text: "Hi {{name}}, order {{ order_id }}"}}
+			},
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components = []whatsappTY.ComponentObject{{Type: "body", Parameters: []whatsappTY.ParameterObject{
+					{Type: "text", ParameterName: "order_id", Text: "#42"},
+					{Type: "text", ParameterName: "name", Text: "Alice"},
+				}}}
+			},
+			want: "Hi Alice, order #42",
+		},
+		{
+			name: "media header",
+			definition: func(d *whatsappTY.Template) {
+				d.Components[0] = whatsappTY.TemplateComponent{Type: whatsappTY.TEMPLATE_COMPONENT_HEADER, Format: whatsappTY.TEMPLATE_FORMAT_IMAGE}
+			},
+			send: func(s *whatsappTY.MessageTemplateObject) {
+				s.Components[0].Parameters = []whatsappTY.ParameterObject{{Type: "image", Image: &whatsappTY.Media{ID: "media-1"}}}
+			},
+			want: "[image: media-1]\n\nHi Alice, your order #42 total $10.00 ships on Monday.\n\nThanks\n\n[Track] [Stop]",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			definition, send := orderTemplate(), orderSend()
+			if tc.definition != nil {
+				tc.definition(definition)
+			}
+			if tc.send != nil {
+				tc.send(send)
+			}
+
+			got, err := Render(definition, send)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
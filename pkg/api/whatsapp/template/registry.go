@@ -0,0 +1,281 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	fileUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/file"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// ErrTemplateNotFound returned when the template is not available in the registry
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ErrStaleTemplates returned by Load, when the source failed and the definitions are loaded from the cache
+var ErrStaleTemplates = errors.New("templates loaded from cache, source unavailable")
+
+// DefaultLoadRetryInterval is the wait time after a failed load, before loading again on Get
+const DefaultLoadRetryInterval = 30 * time.Second
+
+// Source provides the template definitions, TemplateAPI implements it
+type Source interface {
+	ListAll(ctx context.Context, options *whatsappTY.TemplateListOptions) ([]whatsappTY.Template, error)
+}
+
+// Cache stores the template definitions locally
+type Cache interface {
+	Load(ctx context.Context) ([]whatsappTY.Template, error)
+	Save(ctx context.Context, templates []whatsappTY.Template) error
+}
+
+// FileCache keeps the template definitions in a json file
+type FileCache struct {
+	Path string
+}
+
+func (fc *FileCache) Load(ctx context.Context) ([]whatsappTY.Template, error) {
+	data, err := os.ReadFile(fc.Path)
+	if err != nil {
+		return nil, err
+	}
+	templates := []whatsappTY.Template{}
+	err = json.Unmarshal(data, &templates)
+	if err != nil {
+		return nil, fmt.Errorf("error on decoding template cache file[%s]: %w", fc.Path, err)
+	}
+	return templates, nil
+}
+
+func (fc *FileCache) Save(ctx context.Context, templates []whatsappTY.Template) error {
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileUtils.WriteAtomic(fc.Path, data, 0o644)
+}
+
+// Registry keeps the template definitions, used to validate and render the template sends.
+// definitions are loaded from the source, falls back to the cache if the source fails
+type Registry struct {
+	logger        *zap.Logger
+	source        Source
+	cache         Cache
+	ttl           time.Duration
+	retryInterval time.Duration
+	mutex         sync.RWMutex
+	templates     map[string]whatsappTY.Template // key: name + language
+	loadedAt      time.Time
+	loading       *loadCall // in progress load, shared by the concurrent callers
+	loadErr       error     // error of the last load, returned until the retry interval passes
+	failedAt      time.Time
+}
+
+// load call shared by the concurrent callers
+type loadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewRegistry returns a template registry, source and cache are optional.
+// ttl defines the refresh interval from the source, zero disables the refresh
+func NewRegistry(ctx context.Context, source Source, cache Cache, ttl time.Duration) *Registry {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	return &Registry{
+		logger:        logger.Named("template_registry"),
+		source:        source,
+		cache:         cache,
+		ttl:           ttl,
+		retryInterval: DefaultLoadRetryInterval,
+		templates:     map[string]whatsappTY.Template{},
+	}
+}
+
+func registryKey(name, language string) string {
+	return name + "/" + language
+}
+
+// Load loads the template definitions from the source or from the cache,
+// replaces the existing definitions, the templates removed from the source are dropped.
+// on a source failure the cached definitions are used, the source error is returned with ErrStaleTemplates
+// and the registry is kept expired, the source is tried again after the retry interval
+func (r *Registry) Load(ctx context.Context) error {
+	logger := loggerUtils.FromContextOrDefault(ctx, r.logger)
+
+	var templates []whatsappTY.Template
+	var sourceErr error
+	if r.source != nil {
+		templates, sourceErr = r.source.ListAll(ctx, nil)
+		if sourceErr == nil {
+			if r.cache != nil {
+				if err := r.cache.Save(ctx, templates); err != nil {
+					logger.Warn("error on saving templates to cache", zap.Error(err))
+				}
+			}
+			r.replace(templates, true)
+			return nil
+		}
+		logger.Warn("error on loading templates from source, trying cache", zap.Error(sourceErr))
+	}
+
+	if r.cache == nil {
+		if sourceErr != nil {
+			return sourceErr
+		}
+		return errors.New("neither source nor cache configured")
+	}
+
+	templates, err := r.cache.Load(ctx)
+	if err != nil {
+		return errors.Join(sourceErr, fmt.Errorf("error on loading templates from cache: %w", err))
+	}
+	r.replace(templates, sourceErr == nil)
+	if sourceErr != nil {
+		return fmt.Errorf("%w: %w", ErrStaleTemplates, sourceErr)
+	}
+	return nil
+}
+
+// replaces all the template definitions, fresh updates the loaded time
+func (r *Registry) replace(templates []whatsappTY.Template, fresh bool) {
+	replaced := make(map[string]whatsappTY.Template, len(templates))
+	for _, template := range templates {
+		replaced[registryKey(template.Name, template.Language)] = template
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.templates = replaced
+	if fresh {
+		r.loadedAt = time.Now()
+	}
+}
+
+// Set adds or replaces the given template definitions, the other definitions are kept
+func (r *Registry) Set(templates ...whatsappTY.Template) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, template := range templates {
+		r.templates[registryKey(template.Name, template.Language)] = template
+	}
+	r.loadedAt = time.Now()
+}
+
+// List returns all the template definitions
+func (r *Registry) List() []whatsappTY.Template {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	templates := make([]whatsappTY.Template, 0, len(r.templates))
+	for _, template := range r.templates {
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+// Get returns the template definition, refreshes the registry if expired
+func (r *Registry) Get(ctx context.Context, name, language string) (*whatsappTY.Template, error) {
+	r.mutex.RLock()
+	expired := r.loadedAt.IsZero() || (r.ttl > 0 && time.Since(r.loadedAt) > r.ttl)
+	r.mutex.RUnlock()
+
+	if expired && (r.source != nil || r.cache != nil) {
+		err := r.refresh(ctx)
+		if err != nil {
+			loggerUtils.FromContextOrDefault(ctx, r.logger).Warn("error on refreshing templates", zap.Error(err))
+		}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	template, found := r.templates[registryKey(name, language)]
+	if !found {
+		return nil, fmt.Errorf("%w: [name: %s, language: %s]", ErrTemplateNotFound, name, language)
+	}
+	return &template, nil
+}
+
+// loads the definitions, the concurrent callers share a single load.
+// after a failure, returns the same error until the retry interval passes
+func (r *Registry) refresh(ctx context.Context) error {
+	r.mutex.Lock()
+	if call := r.loading; call != nil {
+		r.mutex.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if r.loadErr != nil && time.Since(r.failedAt) < r.retryInterval {
+		err := r.loadErr
+		r.mutex.Unlock()
+		return err
+	}
+	call := &loadCall{done: make(chan struct{})}
+	r.loading = call
+	r.mutex.Unlock()
+
+	call.err = r.Load(ctx)
+
+	r.mutex.Lock()
+	r.loading = nil
+	r.loadErr = call.err
+	if call.err != nil {
+		r.failedAt = time.Now()
+	}
+	r.mutex.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// Validate verifies the parameters of the template send against the definition
+func (r *Registry) Validate(ctx context.Context, send *whatsappTY.MessageTemplateObject) error {
+	if send == nil {
+		return errors.New("template can not be nil")
+	}
+	definition, err := r.Get(ctx, send.Name, send.Language.Code)
+	if err != nil {
+		return err
+	}
+	return ValidateParameters(definition, send)
+}
+
+// Render returns a human readable preview of the template send
+func (r *Registry) Render(ctx context.Context, send *whatsappTY.MessageTemplateObject) (string, error) {
+	if send == nil {
+		return "", errors.New("template can not be nil")
+	}
+	definition, err := r.Get(ctx, send.Name, send.Language.Code)
+	if err != nil {
+		return "", err
+	}
+	return Render(definition, send)
+}
+
+// returns the component of the given type from the definition
+func findComponent(definition *whatsappTY.Template, componentType string) *whatsappTY.TemplateComponent {
+	for index := range definition.Components {
+		if strings.EqualFold(definition.Components[index].Type, componentType) {
+			return &definition.Components[index]
+		}
+	}
+	return nil
+}
+
+// returns the buttons of the definition
+func definitionButtons(definition *whatsappTY.Template) []whatsappTY.TemplateButton {
+	if component := findComponent(definition, whatsappTY.TEMPLATE_COMPONENT_BUTTONS); component != nil {
+		return component.Buttons
+	}
+	return nil
+}
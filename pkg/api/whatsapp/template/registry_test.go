@@ -0,0 +1,184 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// source returns the templates or the error, counts the calls
+type testSource struct {
+	mutex     sync.Mutex
+	calls     int32
+	delay     time.Duration
+	templates []whatsappTY.Template
+	err       error
+}
+
+func (ts *testSource) ListAll(ctx context.Context, options *whatsappTY.TemplateListOptions) ([]whatsappTY.Template, error) {
+	atomic.AddInt32(&ts.calls, 1)
+	time.Sleep(ts.delay)
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	return ts.templates, ts.err
+}
+
+func (ts *testSource) set(templates []whatsappTY.Template, err error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	ts.templates, ts.err = templates, err
+}
+
+func templateNames(templates []whatsappTY.Template) []string {
+	names := []string{}
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRegistryGetSharesLoad(t *testing.T) {
+	source := &testSource{delay: 50 * time.Millisecond, templates: []whatsappTY.Template{{Name: "welcome", Language: "en_US"}}}
+	registry := NewRegistry(context.TODO(), source, nil, 0)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := registry.Get(context.TODO(), "welcome", "en_US"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Errorf("source calls = %d, want 1", calls)
+	}
+}
+
+func TestRegistryGetRetryInterval(t *testing.T) {
+	source := &testSource{err: errors.New("unavailable")}
+	registry := NewRegistry(context.TODO(), source, nil, 0)
+	registry.retryInterval = 50 * time.Millisecond
+
+	for i := 0; i < 5; i++ {
+		if _, err := registry.Get(context.TODO(), "welcome", "en_US"); !errors.Is(err, ErrTemplateNotFound) {
+			t.Fatalf("error = %v, want %v", err, ErrTemplateNotFound)
+		}
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Errorf("source calls = %d, want 1 within the retry interval", calls)
+	}
+
+	// loaded again after the retry interval
+	source.set([]whatsappTY.Template{{Name: "welcome", Language: "en_US"}}, nil)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := registry.Get(context.TODO(), "welcome", "en_US"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Errorf("source calls = %d, want 2", calls)
+	}
+}
+
+func TestRegistryLoadReplaces(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  []whatsappTY.Template
+		second []whatsappTY.Template
+		want   []string
+	}{
+		{
+			name:   "removed template dropped",
+			first:  []whatsappTY.Template{{Name: "a", Language: "en_US"}, {Name: "b", Language: "en_US"}},
+			second: []whatsappTY.Template{{Name: "a", Language: "en_US"}},
+			want:   []string{"a"},
+		},
+		{
+			name:   "empty source clears",
+			first:  []whatsappTY.Template{{Name: "a", Language: "en_US"}},
+			second: []whatsappTY.Template{},
+			want:   []string{},
+		},
+		{
+			name:   "updated definition",
+			first:  []whatsappTY.Template{{Name: "a", Language: "en_US", Status: "PENDING"}},
+			second: []whatsappTY.Template{{Name: "a", Language: "en_US", Status: "APPROVED"}, {Name: "c", Language: "en_US"}},
+			want:   []string{"a", "c"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &testSource{templates: tc.first}
+			registry := NewRegistry(context.TODO(), source, nil, 0)
+			if err := registry.Load(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			source.set(tc.second, nil)
+			if err := registry.Load(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+
+			got := templateNames(registry.List())
+			if len(got) != len(tc.want) {
+				t.Fatalf("templates = %v, want %v", got, tc.want)
+			}
+			for index := range got {
+				if got[index] != tc.want[index] {
+					t.Fatalf("templates = %v, want %v", got, tc.want)
+				}
+			}
+			for _, template := range tc.second {
+				loaded, err := registry.Get(context.TODO(), template.Name, template.Language)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if loaded.Status != template.Status {
+					t.Errorf("status = %q, want %q", loaded.Status, template.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryLoadFallsBackToCache(t *testing.T) {
+	cache := &FileCache{Path: t.TempDir() + "/templates.json"}
+	source := &testSource{templates: []whatsappTY.Template{{Name: "cached", Language: "en_US"}}}
+
+	// the successful load saves the templates into the cache
+	if err := NewRegistry(context.TODO(), source, cache, 0).Load(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	source.set(nil, errors.New("unavailable"))
+	registry := NewRegistry(context.TODO(), source, cache, 0)
+	registry.retryInterval = 50 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		if _, err := registry.Get(context.TODO(), "cached", "en_US"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// one source call for the first load and none within the retry interval
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Errorf("source calls = %d, want 2", calls)
+	}
+	if err := registry.Load(context.TODO()); !errors.Is(err, ErrStaleTemplates) {
+		t.Errorf("load error = %v, want %v", err, ErrStaleTemplates)
+	}
+
+	// the source is tried again after the retry interval, not after the ttl
+	source.set([]whatsappTY.Template{{Name: "fresh", Language: "en_US"}}, nil)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := registry.Get(context.TODO(), "fresh", "en_US"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
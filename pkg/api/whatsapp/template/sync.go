@@ -0,0 +1,311 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFiles loads the template definitions from the yaml files (*.yaml, *.yml) of the directory.
+// a file can hold a template, a list of templates or many yaml documents.
+// field names are same as the graph api, example:
+//
+//	name: order_update
+//	language: en_US
+//	category: UTILITY
+//	components:
+//	  - type: BODY
+//	    text: "Hi {{1}}, your order is on the way"
+//	    example:
+//	      body_text: [["Bob"]]
+func LoadFiles(dir string) ([]whatsappTY.Template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []whatsappTY.Template{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		filename := filepath.Join(dir, entry.Name())
+		fileTemplates, err := LoadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, fileTemplates...)
+	}
+
+	// verify duplicates
+	found := map[string]bool{}
+	for _, template := range templates {
+		key := registryKey(template.Name, template.Language)
+		if found[key] {
+			return nil, fmt.Errorf("duplicate template definition [name: %s, language: %s]", template.Name, template.Language)
+		}
+		found[key] = true
+	}
+	return templates, nil
+}
+
+// LoadFile loads the template definitions from a yaml file
+func LoadFile(filename string) ([]whatsappTY.Template, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []whatsappTY.Template{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error on decoding file[%s]: %w", filename, err)
+		}
+		if document == nil {
+			continue
+		}
+
+		// yaml to json, to reuse the json field names of the template
+		jsonBytes, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("error on converting file[%s]: %w", filename, err)
+		}
+		if _, isList := document.([]any); isList {
+			list := []whatsappTY.Template{}
+			err = json.Unmarshal(jsonBytes, &list)
+			templates = append(templates, list...)
+		} else {
+			template := whatsappTY.Template{}
+			err = json.Unmarshal(jsonBytes, &template)
+			templates = append(templates, template)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error on decoding template in file[%s]: %w", filename, err)
+		}
+	}
+
+	for _, template := range templates {
+		if template.Name == "" || template.Language == "" {
+			return nil, fmt.Errorf("name and language required for the templates in file[%s]", filename)
+		}
+	}
+	return templates, nil
+}
+
+// TemplateChange is a template differs between the local definition and the account
+type TemplateChange struct {
+	Local  whatsappTY.Template
+	Remote whatsappTY.Template
+}
+
+// Plan is the difference between the local definitions and the account
+type Plan struct {
+	Create     []whatsappTY.Template // available only in local
+	Update     []TemplateChange      // differs in category or components, or rejected or paused in the account
+	Delete     []whatsappTY.Template // available only in the account
+	Rejected   []whatsappTY.Template // rejected in the account and defined locally
+	Unchanged  []whatsappTY.Template
+	SkipDelete bool // deletes are reported, but not applied (prune disabled)
+}
+
+// HasChanges reports the plan has create, update or delete to apply
+func (p *Plan) HasChanges() bool {
+	return len(p.Create) > 0 || len(p.Update) > 0 || (len(p.Delete) > 0 && !p.SkipDelete)
+}
+
+// Print writes the plan in human readable format
+func (p *Plan) Print(w io.Writer) error {
+	lines := []string{}
+	for _, template := range p.Create {
+		lines = append(lines, fmt.Sprintf("+ create %s/%s (%s)", template.Name, template.Language, template.Category))
+	}
+	for _, change := range p.Update {
+		lines = append(lines, fmt.Sprintf("~ update %s/%s (id: %s, status: %s)", change.Local.Name, change.Local.Language, change.Remote.ID, change.Remote.Status))
+	}
+	for _, template := range p.Delete {
+		if p.SkipDelete {
+			lines = append(lines, fmt.Sprintf("  skip delete %s/%s (id: %s, prune disabled)", template.Name, template.Language, template.ID))
+		} else {
+			lines = append(lines, fmt.Sprintf("- delete %s/%s (id: %s)", template.Name, template.Language, template.ID))
+		}
+	}
+	for _, template := range p.Rejected {
+		lines = append(lines, fmt.Sprintf("! rejected %s/%s (reason: %s)", template.Name, template.Language, template.RejectedReason))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "no changes")
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// Diff compares the local definitions with the templates in the account
+func Diff(local, remote []whatsappTY.Template) *Plan {
+	plan := &Plan{}
+
+	remoteTemplates := map[string]whatsappTY.Template{}
+	for _, template := range remote {
+		remoteTemplates[registryKey(template.Name, template.Language)] = template
+	}
+
+	localKeys := map[string]bool{}
+	for _, localTemplate := range local {
+		key := registryKey(localTemplate.Name, localTemplate.Language)
+		localKeys[key] = true
+
+		remoteTemplate, found := remoteTemplates[key]
+		if !found {
+			plan.Create = append(plan.Create, localTemplate)
+			continue
+		}
+		rejected := strings.EqualFold(remoteTemplate.Status, whatsappTY.TEMPLATE_STATUS_REJECTED)
+		if rejected {
+			plan.Rejected = append(plan.Rejected, remoteTemplate)
+		}
+		// rejected and paused templates are resubmitted, even if the definition is same
+		resubmit := rejected || strings.EqualFold(remoteTemplate.Status, whatsappTY.TEMPLATE_STATUS_PAUSED)
+		if !resubmit && templateEqual(localTemplate, remoteTemplate) {
+			plan.Unchanged = append(plan.Unchanged, remoteTemplate)
+		} else {
+			plan.Update = append(plan.Update, TemplateChange{Local: localTemplate, Remote: remoteTemplate})
+		}
+	}
+
+	for key, remoteTemplate := range remoteTemplates {
+		if !localKeys[key] {
+			plan.Delete = append(plan.Delete, remoteTemplate)
+		}
+	}
+	sort.Slice(plan.Delete, func(i, j int) bool {
+		return registryKey(plan.Delete[i].Name, plan.Delete[i].Language) < registryKey(plan.Delete[j].Name, plan.Delete[j].Language)
+	})
+
+	return plan
+}
+
+// compares the category and the components
+func templateEqual(local, remote whatsappTY.Template) bool {
+	if local.Category != "" && !strings.EqualFold(local.Category, remote.Category) {
+		return false
+	}
+	return normalizeComponents(local.Components) == normalizeComponents(remote.Components)
+}
+
+// returns a comparable form of the components
+func normalizeComponents(components []whatsappTY.TemplateComponent) string {
+	normalized := make([]whatsappTY.TemplateComponent, 0, len(components))
+	for _, component := range components {
+		component.Type = strings.ToUpper(component.Type)
+		component.Format = strings.ToUpper(component.Format)
+		// examples are not returned consistently by the api
+		component.Example = nil
+		buttons := make([]whatsappTY.TemplateButton, 0, len(component.Buttons))
+		for _, button := range component.Buttons {
+			button.Type = strings.ToUpper(button.Type)
+			button.Example = nil
+			buttons = append(buttons, button)
+		}
+		component.Buttons = buttons
+		normalized = append(normalized, component)
+	}
+	data, _ := json.Marshal(normalized)
+	return string(data)
+}
+
+// Manager manages the templates in the account, TemplateAPI implements it
+type Manager interface {
+	ListAll(ctx context.Context, options *whatsappTY.TemplateListOptions) ([]whatsappTY.Template, error)
+	Create(ctx context.Context, template *whatsappTY.Template) (*whatsappTY.TemplateCreateResponse, error)
+	Edit(ctx context.Context, templateID string, template *whatsappTY.Template) error
+	DeleteByID(ctx context.Context, name, hsmID string) error
+}
+
+type SyncOptions struct {
+	DryRun bool      // prints the plan, no changes applied
+	Prune  bool      // deletes the templates not defined locally
+	Output io.Writer // plan and progress written here, optional
+}
+
+// Sync applies the local definitions to the account.
+// returns the plan computed against the account
+func Sync(ctx context.Context, manager Manager, local []whatsappTY.Template, options SyncOptions) (*Plan, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, zap.NewNop())
+	output := options.Output
+	if output == nil {
+		output = io.Discard
+	}
+
+	remote, err := manager.ListAll(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error on listing templates: %w", err)
+	}
+
+	plan := Diff(local, remote)
+	plan.SkipDelete = !options.Prune
+	err = plan.Print(output)
+	if err != nil {
+		return plan, err
+	}
+	if options.DryRun {
+		return plan, nil
+	}
+
+	errs := []error{}
+	for index := range plan.Create {
+		template := &plan.Create[index]
+		resp, err := manager.Create(ctx, template)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error on creating template %s/%s: %w", template.Name, template.Language, err))
+			continue
+		}
+		logger.Info("template created", zap.String("name", template.Name), zap.String("language", template.Language), zap.String("id", resp.ID), zap.String("status", resp.Status))
+		fmt.Fprintf(output, "created %s/%s (id: %s, status: %s)\n", template.Name, template.Language, resp.ID, resp.Status)
+	}
+
+	for index := range plan.Update {
+		change := &plan.Update[index]
+		err := manager.Edit(ctx, change.Remote.ID, &change.Local)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error on updating template %s/%s: %w", change.Local.Name, change.Local.Language, err))
+			continue
+		}
+		logger.Info("template updated", zap.String("name", change.Local.Name), zap.String("language", change.Local.Language), zap.String("id", change.Remote.ID))
+		fmt.Fprintf(output, "updated %s/%s\n", change.Local.Name, change.Local.Language)
+	}
+
+	deletes := plan.Delete
+	if plan.SkipDelete {
+		deletes = nil
+	}
+	for index := range deletes {
+		template := &deletes[index]
+		err := manager.DeleteByID(ctx, template.Name, template.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error on deleting template %s/%s: %w", template.Name, template.Language, err))
+			continue
+		}
+		logger.Info("template deleted", zap.String("name", template.Name), zap.String("language", template.Language), zap.String("id", template.ID))
+		fmt.Fprintf(output, "deleted %s/%s\n", template.Name, template.Language)
+	}
+
+	return plan, errors.Join(errs...)
+}
@@ -0,0 +1,293 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+func bodyTemplate(name, text, status string) whatsappTY.Template {
+	return whatsappTY.Template{
+		ID:         "id-" + name,
+		Name:       name,
+		Language:   "en_US",
+		Category:   whatsappTY.TEMPLATE_CATEGORY_UTILITY,
+		Status:     status,
+		Components: []whatsappTY.TemplateComponent{{Type: whatsappTY.TEMPLATE_COMPONENT_BODY, Text: text}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type names struct {
+		create, update, delete, rejected, unchanged []string
+	}
+
+	tests := []struct {
+		name   string
+		local  []whatsappTY.Template
+		remote []whatsappTY.Template
+		want   names
+	}{
+		{
+			name:   "create",
+			local:  []whatsappTY.Template{bodyTemplate("a", "hi", "")},
+			remote: nil,
+			want:   names{create: []string{"a"}},
+		},
+		{
+			name:   "unchanged",
+			local:  []whatsappTY.Template{bodyTemplate("a", "hi", "")},
+			remote: []whatsappTY.Template{bodyTemplate("a", "hi", whatsappTY.TEMPLATE_STATUS_APPROVED)},
+			want:   names{unchanged: []string{"a"}},
+		},
+		{
+			name: "case and examples ignored",
+			local: []whatsappTY.Template{{Name: "a", Language: "en_US", Components: []whatsappTY.TemplateComponent{
+				{Type: "body", Text: "hi {{1}}", Example: &whatsappTY.TemplateExample{BodyText: [][]string{{"Bob"}}}},
+			}}},
+			remote: []whatsappTY.Template{{Name: "a", Language: "en_US", Category: "UTILITY", Components: []whatsappTY.TemplateComponent{
+				{Type: "BODY", Text: "hi {{1}}"},
+			}}},
+			want: names{unchanged: []string{"a"}},
+		},
+		{
+			name:   "text changed",
+			local:  []whatsappTY.Template{bodyTemplate("a", "hello", "")},
+			remote: []whatsappTY.Template{bodyTemplate("a", "hi", whatsappTY.TEMPLATE_STATUS_APPROVED)},
+			want:   names{update: []string{"a"}},
+		},
+		{
+			name: "category changed",
+			local: []whatsappTY.Template{func() whatsappTY.Template {
+				template := bodyTemplate("a", "hi", "")
+				template.Category = whatsappTY.TEMPLATE_CATEGORY_MARKETING
+				return template
+			}()},
+			remote: []whatsappTY.Template{bodyTemplate("a", "hi", whatsappTY.TEMPLATE_STATUS_APPROVED)},
+			want:   names{update: []string{"a"}},
+		},
+		{
+			name:   "rejected resubmitted",
+			local:  []whatsappTY.Template{bodyTemplate("a", "hi", "")},
+			remote: []whatsappTY.Template{bodyTemplate("a", "hi", whatsappTY.TEMPLATE_STATUS_REJECTED)},
+			want:   names{update: []string{"a"}, rejected: []string{"a"}},
+		},
+		{
+			name:   "paused resubmitted",
+			local:  []whatsappTY.Template{bodyTemplate("a", "hi", "")},
+			remote: []whatsappTY.Template{bodyTemplate("a", "hi", whatsappTY.TEMPLATE_STATUS_PAUSED)},
+			want:   names{update: []string{"a"}},
+		},
+		{
+			name:   "remote only deleted",
+			local:  []whatsappTY.Template{bodyTemplate("a", "hi", "")},
+			remote: []whatsappTY.Template{bodyTemplate("c", "hi", ""), bodyTemplate("a", "hi", ""), bodyTemplate("b", "hi", "")},
+			want:   names{unchanged: []string{"a"}, delete: []string{"b", "c"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := Diff(tc.local, tc.remote)
+
+			templateNames := func(templates []whatsappTY.Template) []string {
+				names := []string{}
+				for _, template := range templates {
+					names = append(names, template.Name)
+				}
+				return names
+			}
+			updateNames := []string{}
+			for _, change := range plan.Update {
+				updateNames = append(updateNames, change.Local.Name)
+			}
+			got := names{
+				create:    templateNames(plan.Create),
+				update:    updateNames,
+				delete:    templateNames(plan.Delete),
+				rejected:  templateNames(plan.Rejected),
+				unchanged: templateNames(plan.Unchanged),
+			}
+			want := tc.want
+			for _, list := range []*[]string{&want.create, &want.update, &want.delete, &want.rejected, &want.unchanged} {
+				if *list == nil {
+					*list = []string{}
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// manager records the calls
+type testManager struct {
+	remote  []whatsappTY.Template
+	created []string
+	updated []string
+	deleted []string
+}
+
+func (tm *testManager) ListAll(ctx context.Context, options *whatsappTY.TemplateListOptions) ([]whatsappTY.Template, error) {
+	return tm.remote, nil
+}
+
+func (tm *testManager) Create(ctx context.Context, template *whatsappTY.Template) (*whatsappTY.TemplateCreateResponse, error) {
+	tm.created = append(tm.created, template.Name)
+	return &whatsappTY.TemplateCreateResponse{ID: "new-" + template.Name, Status: whatsappTY.TEMPLATE_STATUS_PENDING}, nil
+}
+
+func (tm *testManager) Edit(ctx context.Context, templateID string, template *whatsappTY.Template) error {
+	tm.updated = append(tm.updated, templateID)
+	return nil
+}
+
+func (tm *testManager) DeleteByID(ctx context.Context, name, hsmID string) error {
+	tm.deleted = append(tm.deleted, hsmID)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	local := []whatsappTY.Template{bodyTemplate("a", "hello", ""), bodyTemplate("new", "hi", "")}
+	remote := []whatsappTY.Template{bodyTemplate("a", "hi", whatsappTY.TEMPLATE_STATUS_APPROVED), bodyTemplate("old", "hi", whatsappTY.TEMPLATE_STATUS_APPROVED)}
+
+	tests := []struct {
+		name        string
+		options     SyncOptions
+		wantCreated []string
+		wantUpdated []string
+		wantDeleted []string
+		wantOutput  string
+	}{
+		{
+			name:        "without prune",
+			options:     SyncOptions{},
+			wantCreated: []string{"new"},
+			wantUpdated: []string{"id-a"},
+			wantOutput:  "skip delete old/en_US (id: id-old, prune disabled)",
+		},
+		{
+			name:        "with prune",
+			options:     SyncOptions{Prune: true},
+			wantCreated: []string{"new"},
+			wantUpdated: []string{"id-a"},
+			wantDeleted: []string{"id-old"},
+			wantOutput:  "- delete old/en_US (id: id-old)",
+		},
+		{
+			name:       "dry run",
+			options:    SyncOptions{DryRun: true, Prune: true},
+			wantOutput: "+ create new/en_US (UTILITY)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &testManager{remote: remote}
+			output := &bytes.Buffer{}
+			tc.options.Output = output
+
+			plan, err := Sync(context.TODO(), manager, local, tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(plan.Delete) != 1 {
+				t.Errorf("plan deletes = %d, want 1", len(plan.Delete))
+			}
+			if plan.SkipDelete == tc.options.Prune {
+				t.Errorf("skip delete = %v, prune %v", plan.SkipDelete, tc.options.Prune)
+			}
+			for _, check := range []struct {
+				name      string
+				got, want []string
+			}{
+				{name: "created", got: manager.created, want: tc.wantCreated},
+				{name: "updated", got: manager.updated, want: tc.wantUpdated},
+				{name: "deleted", got: manager.deleted, want: tc.wantDeleted},
+			} {
+				sort.Strings(check.got)
+				if len(check.got) != len(check.want) || (len(check.want) > 0 && !reflect.DeepEqual(check.got, check.want)) {
+					t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+				}
+			}
+			if !strings.Contains(output.String(), tc.wantOutput) {
+				t.Errorf("output %q does not contain %q", output.String(), tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestPlanHasChanges(t *testing.T) {
+	deleteOnly := &Plan{Delete: []whatsappTY.Template{bodyTemplate("a", "hi", "")}}
+	if !deleteOnly.HasChanges() {
+		t.Error("delete expected as a change")
+	}
+	deleteOnly.SkipDelete = true
+	if deleteOnly.HasChanges() {
+		t.Error("skipped delete is not a change")
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "single, list and documents",
+			files: map[string]string{
+				"single.yaml": "name: a\nlanguage: en_US\ncategory: UTILITY\n",
+				"list.yml":    "- name: b\n  language: en_US\n- name: c\n  language: en_US\n",
+				"docs.yaml":   "name: d\nlanguage: en_US\n---\nname: e\nlanguage: en_US\n",
+				"ignored.txt": "name: x\nlanguage: en_US\n",
+			},
+			want: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:    "duplicate",
+			files:   map[string]string{"a.yaml": "name: a\nlanguage: en_US\n", "b.yaml": "name: a\nlanguage: en_US\n"},
+			wantErr: "duplicate template definition",
+		},
+		{
+			name:    "missing language",
+			files:   map[string]string{"a.yaml": "name: a\n"},
+			wantErr: "name and language required",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			templates, err := LoadFiles(dir)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, template := range templates {
+				names = append(names, template.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.want) {
+				t.Errorf("templates = %v, want %v", names, tc.want)
+			}
+		})
+	}
+}
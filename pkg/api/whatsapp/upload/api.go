@@ -0,0 +1,235 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultChunkSize  = 4 * 1024 * 1024 // 4 MiB
+	DefaultMaxResumes = 3
+)
+
+// ProgressFunc reports the uploaded bytes
+type ProgressFunc func(uploaded, total int64)
+
+// Options of the resumable upload
+type Options struct {
+	ChunkSize  int64        // bytes per request, default: 4 MiB
+	MaxResumes int          // resume attempts on a failed chunk, default: 3, negative disables
+	Progress   ProgressFunc // optional
+}
+
+func (o *Options) withDefaults() Options {
+	options := Options{}
+	if o != nil {
+		options = *o
+	}
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultChunkSize
+	}
+	if options.MaxResumes == 0 {
+		options.MaxResumes = DefaultMaxResumes
+	} else if options.MaxResumes < 0 {
+		options.MaxResumes = 0
+	}
+	return options
+}
+
+// UploadAPI uploads files with the resumable upload api,
+// the returned file handle is used in profile picture and template header samples
+// https://developers.facebook.com/docs/graph-api/guides/upload
+type UploadAPI struct {
+	logger *zap.Logger
+	appID  string
+	client *customClient.Client
+}
+
+func New(ctx context.Context, client *customClient.Client, appID string) *UploadAPI {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	return &UploadAPI{
+		appID:  appID,
+		client: client,
+		logger: logger.Named("upload_api"),
+	}
+}
+
+// CreateSession creates an upload session for the file
+func (u *UploadAPI) CreateSession(ctx context.Context, fileName string, fileLength int64, fileType string) (*whatsappTY.UploadSession, error) {
+	if u.appID == "" {
+		return nil, errors.New("app id can not be empty")
+	}
+	// /{{App-ID}}/uploads
+	api := fmt.Sprintf("/%s/uploads", u.appID)
+	queryParams := map[string]any{
+		"file_name":   fileName,
+		"file_length": fileLength,
+		"file_type":   fileType,
+	}
+	out := &whatsappTY.UploadSession{}
+	err := u.client.Post(ctx, api, nil, queryParams, nil, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOffset returns the number of bytes received by the upload session
+func (u *UploadAPI) GetOffset(ctx context.Context, sessionID string) (int64, error) {
+	// /{{Upload-Session-ID}}
+	api := fmt.Sprintf("/%s", sessionID)
+	out := &whatsappTY.UploadSession{}
+	err := u.client.Get(ctx, api, nil, nil, out)
+	if err != nil {
+		return 0, err
+	}
+	return out.FileOffset, nil
+}
+
+// UploadChunk uploads the data from the offset,
+// the file handle is returned once the upload is completed
+func (u *UploadAPI) UploadChunk(ctx context.Context, sessionID string, offset int64, chunk io.ReadSeeker) (*whatsappTY.UploadResult, error) {
+	// /{{Upload-Session-ID}}
+	api := fmt.Sprintf("/%s", sessionID)
+	headers := map[string]string{
+		"Content-Type": "application/octet-stream",
+		"file_offset":  fmt.Sprintf("%d", offset),
+	}
+	out := &whatsappTY.UploadResult{}
+	err := u.client.Post(ctx, api, headers, nil, chunk, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Upload creates a session and uploads the source in chunks, returns the file handle.
+// the session id is returned on failure too, can be used to resume the upload later
+func (u *UploadAPI) Upload(ctx context.Context, source io.ReaderAt, size int64, fileName, fileType string, options *Options) (string, string, error) {
+	if size <= 0 {
+		return "", "", fmt.Errorf("invalid file size %d, file:%s", size, fileName)
+	}
+	session, err := u.CreateSession(ctx, fileName, size, fileType)
+	if err != nil {
+		return "", "", err
+	}
+	loggerUtils.FromContextOrDefault(ctx, u.logger).Debug("upload session created", zap.String("sessionId", session.ID), zap.String("file", fileName), zap.Int64("size", size))
+
+	handle, err := u.upload(ctx, session.ID, source, size, 0, options)
+	return handle, session.ID, err
+}
+
+// Resume continues the interrupted upload from the offset received by the session
+func (u *UploadAPI) Resume(ctx context.Context, sessionID string, source io.ReaderAt, size int64, options *Options) (string, error) {
+	offset, err := u.GetOffset(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("error on getting the offset of the session[%s]: %w", sessionID, err)
+	}
+	return u.upload(ctx, sessionID, source, size, offset, options)
+}
+
+func (u *UploadAPI) upload(ctx context.Context, sessionID string, source io.ReaderAt, size, offset int64, options *Options) (string, error) {
+	logger := loggerUtils.FromContextOrDefault(ctx, u.logger)
+	opts := options.withDefaults()
+
+	if size <= 0 {
+		return "", fmt.Errorf("invalid file size %d, session:%s", size, sessionID)
+	}
+	if offset < 0 || offset > size {
+		return "", fmt.Errorf("invalid offset %d for the file size %d, session:%s", offset, size, sessionID)
+	}
+
+	resumes := 0
+	// continues from the offset received by the server, counted against the max resumes
+	resume := func(cause error) error {
+		if ctx.Err() != nil || resumes >= opts.MaxResumes {
+			return cause
+		}
+		resumes++
+		serverOffset, err := u.GetOffset(ctx, sessionID)
+		if err != nil {
+			return errors.Join(cause, err)
+		}
+		if serverOffset < 0 || serverOffset > size {
+			return errors.Join(cause, fmt.Errorf("invalid server offset %d for the file size %d, session:%s", serverOffset, size, sessionID))
+		}
+		logger.Warn("resuming upload", zap.String("sessionId", sessionID), zap.Int64("offset", serverOffset), zap.Int("resume", resumes), zap.Error(cause))
+		offset = serverOffset
+		return nil
+	}
+
+	for {
+		if opts.Progress != nil {
+			opts.Progress(offset, size)
+		}
+
+		chunkSize := opts.ChunkSize
+		if remaining := size - offset; remaining < chunkSize {
+			chunkSize = remaining
+		}
+
+		result, err := u.UploadChunk(ctx, sessionID, offset, io.NewSectionReader(source, offset, chunkSize))
+		if err != nil {
+			// the server might have received a part of the chunk
+			if resumeErr := resume(err); resumeErr != nil {
+				return "", resumeErr
+			}
+			continue
+		}
+
+		offset += chunkSize
+		if result.Handle != "" {
+			if opts.Progress != nil {
+				opts.Progress(size, size)
+			}
+			return result.Handle, nil
+		}
+		if offset >= size {
+			// all the bytes sent, but no handle received
+			missingErr := fmt.Errorf("upload completed, but file handle not received for the session[%s]", sessionID)
+			if resumeErr := resume(missingErr); resumeErr != nil {
+				return "", resumeErr
+			}
+			if offset >= size {
+				return "", missingErr
+			}
+		}
+	}
+}
+
+// UploadFile uploads the file and returns the file handle
+func (u *UploadAPI) UploadFile(ctx context.Context, filePath string, options *Options) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("error on opening a file[%s]: %w", filePath, err)
+	}
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			u.logger.Error("error on closing a file", zap.String("file", filePath), zap.Error(err))
+		}
+	}()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("error on getting file info[%s]: %w", filePath, err)
+	}
+
+	fileName := filepath.Base(filePath)
+	fileType := mime.TypeByExtension(filepath.Ext(fileName))
+	handle, _, err := u.Upload(ctx, file, stat.Size(), fileName, fileType, options)
+	return handle, err
+}
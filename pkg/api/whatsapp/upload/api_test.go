@@ -0,0 +1,182 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// resumable upload server, keeps the received bytes of a single session
+type testServer struct {
+	mutex      sync.Mutex
+	size       int64
+	received   []byte
+	failChunks int                        // chunk requests failed before accepting
+	noHandle   bool                       // the handle is never returned
+	offset     func(received int64) int64 // offset reported on get, default: received bytes
+	chunks     int
+	gets       int
+}
+
+func (ts *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/app-1/uploads":
+		size, _ := strconv.ParseInt(r.URL.Query().Get("file_length"), 10, 64)
+		ts.size = size
+		fmt.Fprint(w, `{"id":"session-1"}`)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/session-1":
+		ts.chunks++
+		if ts.failChunks > 0 {
+			ts.failChunks--
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"message":"chunk failed","code":2}}`)
+			return
+		}
+		offset, _ := strconv.ParseInt(r.Header.Get("file_offset"), 10, 64)
+		if offset > int64(len(ts.received)) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"invalid offset","code":100}}`)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		ts.received = append(ts.received[:offset], data...)
+		if int64(len(ts.received)) >= ts.size && !ts.noHandle {
+			fmt.Fprint(w, `{"h":"handle-1"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/session-1":
+		ts.gets++
+		offset := int64(len(ts.received))
+		if ts.offset != nil {
+			offset = ts.offset(offset)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "session-1", "file_offset": offset})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestUploadAPI(t *testing.T, server *testServer) *UploadAPI {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	ctx := loggerUtils.WithContext(context.TODO(), zap.NewNop())
+	client, err := customClient.New(ctx, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(ctx, client, "app-1")
+}
+
+func TestUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10) // 100 bytes
+
+	tests := []struct {
+		name       string
+		server     *testServer
+		content    []byte
+		maxResumes int
+		wantHandle string
+		wantErr    bool
+		wantChunks int
+		wantGets   int
+	}{
+		{name: "uploaded in chunks", server: &testServer{}, content: content, wantHandle: "handle-1", wantChunks: 4},
+		{name: "resumed after a failed chunk", server: &testServer{failChunks: 1}, content: content, wantHandle: "handle-1", wantChunks: 5, wantGets: 1},
+		{name: "failed chunks over max resumes", server: &testServer{failChunks: 10}, content: content, maxResumes: 2, wantErr: true, wantChunks: 3, wantGets: 2},
+		{name: "handle not received", server: &testServer{noHandle: true}, content: content, wantErr: true, wantChunks: 4, wantGets: 1},
+		{
+			name:       "short server offset",
+			server:     &testServer{noHandle: true, offset: func(received int64) int64 { return received - 10 }},
+			content:    content,
+			maxResumes: 3,
+			wantErr:    true,
+			wantChunks: 7, // 4 chunks and a resent chunk per resume
+			wantGets:   3,
+		},
+		{name: "empty file", server: &testServer{}, content: []byte{}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newTestUploadAPI(t, tc.server)
+			options := &Options{ChunkSize: 30, MaxResumes: tc.maxResumes}
+
+			handle, _, err := api.Upload(context.TODO(), bytes.NewReader(tc.content), int64(len(tc.content)), "file.pdf", "application/pdf", options)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if handle != tc.wantHandle {
+				t.Errorf("handle = %q, want %q", handle, tc.wantHandle)
+			}
+			if tc.server.chunks != tc.wantChunks {
+				t.Errorf("chunk requests = %d, want %d", tc.server.chunks, tc.wantChunks)
+			}
+			if tc.server.gets != tc.wantGets {
+				t.Errorf("offset requests = %d, want %d", tc.server.gets, tc.wantGets)
+			}
+			if tc.wantHandle != "" && !bytes.Equal(tc.server.received, tc.content) {
+				t.Errorf("received %d bytes, want %d", len(tc.server.received), len(tc.content))
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10)
+
+	tests := []struct {
+		name       string
+		received   int
+		size       int64
+		wantErr    bool
+		wantChunks int
+	}{
+		{name: "from the server offset", received: 60, size: 100, wantChunks: 2},
+		{name: "offset over the size", received: 60, size: 50, wantErr: true},
+		{name: "zero size", received: 0, size: 0, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &testServer{size: 100, received: append([]byte{}, content[:tc.received]...)}
+			api := newTestUploadAPI(t, server)
+
+			progress := []int64{}
+			options := &Options{ChunkSize: 30, Progress: func(uploaded, total int64) { progress = append(progress, uploaded) }}
+			handle, err := api.Resume(context.TODO(), "session-1", bytes.NewReader(content), tc.size, options)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tc.wantErr)
+			}
+			if server.chunks != tc.wantChunks {
+				t.Errorf("chunk requests = %d, want %d", server.chunks, tc.wantChunks)
+			}
+			if tc.wantErr {
+				return
+			}
+			if handle != "handle-1" {
+				t.Errorf("handle = %q", handle)
+			}
+			if len(progress) == 0 || progress[0] != int64(tc.received) || progress[len(progress)-1] != tc.size {
+				t.Errorf("progress = %v", progress)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package ledger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"github.com/jkandasa/whatsapp-cloud-api/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+// order of the delivery statuses, a status never moves back to a lower rank.
+// status webhooks can arrive out of order, example: read before delivered
+var statusRank = map[string]int{
+	whatsappTY.MESSAGE_STATUS_ACCEPTED:                    1,
+	whatsappTY.MESSAGE_STATUS_HELD_FOR_QUALITY_ASSESSMENT: 2,
+	whatsappTY.MESSAGE_STATUS_SENT:                        3,
+	whatsappTY.MESSAGE_STATUS_DELIVERED:                   4,
+	whatsappTY.MESSAGE_STATUS_READ:                        5,
+	whatsappTY.MESSAGE_STATUS_FAILED:                      6,
+}
+
+// Ledger records the outbound messages and tracks the delivery status from the status webhooks.
+// implements the message api recorder
+type Ledger struct {
+	logger *zap.Logger
+	store  Store
+	mutex  sync.Mutex
+}
+
+// New returns a ledger, keeps the records in memory if the store is nil
+func New(ctx context.Context, store Store) *Ledger {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Ledger{
+		logger: logger.Named("message_ledger"),
+		store:  store,
+	}
+}
+
+// updates the record of the message id, creates a new record if not available
+func (l *Ledger) update(ctx context.Context, messageID string, updateFn func(record *Record)) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	record, err := l.store.Get(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	updated := Record{MessageID: messageID}
+	if record != nil {
+		// the store can return a shared record, changed on a copy
+		updated = record.clone()
+	}
+	if updated.StatusTimes == nil {
+		updated.StatusTimes = map[string]time.Time{}
+	}
+	updateFn(&updated)
+	updated.UpdatedAt = time.Now()
+	return l.store.Save(ctx, updated)
+}
+
+// applies the status, keeps the higher ranked status
+func applyStatus(record *Record, status string, statusTime time.Time) {
+	if existing, found := record.StatusTimes[status]; !found || statusTime.Before(existing) {
+		record.StatusTimes[status] = statusTime
+	}
+	if statusRank[status] > statusRank[record.Status] {
+		record.Status = status
+	}
+}
+
+// Record records the posted message
+func (l *Ledger) Record(ctx context.Context, phoneNumberID string, message whatsappTY.Message, response *whatsappTY.MessageResponse) error {
+	messageID := response.MessageID()
+	if messageID == "" {
+		return nil
+	}
+	recipient := response.RecipientWaID()
+	if recipient == "" {
+		recipient = customClient.NormalizePhoneNumber(message.To)
+	}
+	status := response.MessageStatus()
+	if status == "" {
+		status = whatsappTY.MESSAGE_STATUS_ACCEPTED
+	}
+
+	now := time.Now()
+	return l.update(ctx, messageID, func(record *Record) {
+		record.PhoneNumberID = phoneNumberID
+		record.Recipient = recipient
+		record.Type = message.Type
+		if message.Template != nil {
+			record.TemplateName = message.Template.Name
+		}
+		record.BizOpaqueCallbackData = message.BizOpaqueCallbackData
+		record.SentAt = now
+		applyStatus(record, status, now)
+	})
+}
+
+// RecordSent records the posted message, errors are logged
+func (l *Ledger) RecordSent(ctx context.Context, phoneNumberID string, message whatsappTY.Message, response *whatsappTY.MessageResponse) {
+	if err := l.Record(ctx, phoneNumberID, message, response); err != nil {
+		logger := loggerUtils.FromContextOrDefault(ctx, l.logger)
+		logger.Error("error on recording the message", zap.String("messageId", response.MessageID()), zap.Error(err))
+	}
+}
+
+// UpdateStatus updates the record from the status webhook.
+// the record is created if the status received before the message recorded
+func (l *Ledger) UpdateStatus(ctx context.Context, phoneNumberID string, status *whatsappTY.MessageStatus) error {
+	if status == nil || status.ID == "" {
+		return nil
+	}
+	statusTime := status.Time()
+	if statusTime.IsZero() {
+		statusTime = time.Now()
+	}
+	return l.update(ctx, status.ID, func(record *Record) {
+		if record.PhoneNumberID == "" {
+			record.PhoneNumberID = phoneNumberID
+		}
+		if record.Recipient == "" {
+			record.Recipient = status.RecipientID
+		}
+		if record.BizOpaqueCallbackData == "" {
+			record.BizOpaqueCallbackData = status.BizOpaqueCallbackData
+		}
+		if status.Status == whatsappTY.MESSAGE_STATUS_FAILED {
+			record.Errors = status.Errors
+		}
+		applyStatus(record, status.Status, statusTime)
+	})
+}
+
+// Get returns the record of the message id (wamid), nil if not available
+func (l *Ledger) Get(ctx context.Context, messageID string) (*Record, error) {
+	return l.store.Get(ctx, messageID)
+}
+
+// Query returns the records matching the query
+func (l *Ledger) Query(ctx context.Context, query Query) ([]Record, error) {
+	query.Recipient = customClient.NormalizePhoneNumber(query.Recipient)
+	return l.store.Query(ctx, query)
+}
+
+// Middleware updates the records from the status events of the webhook
+func (l *Ledger) Middleware() webhook.Middleware {
+	return func(next webhook.HandlerFunc) webhook.HandlerFunc {
+		return func(ctx context.Context, event *webhook.Event) error {
+			if event.Kind == webhook.EventKindStatus {
+				if err := l.UpdateStatus(ctx, event.PhoneNumberID, event.Status); err != nil {
+					logger := loggerUtils.FromContextOrDefault(ctx, l.logger)
+					logger.Error("error on updating the message status", zap.String("phoneNumberId", event.PhoneNumberID), zap.Error(err))
+				}
+			}
+			return next(ctx, event)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+var testBase = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// returns a status webhook, the seconds are added to the base time
+func testStatus(status string, seconds int) *whatsappTY.MessageStatus {
+	return &whatsappTY.MessageStatus{
+		ID:          "wamid-1",
+		Status:      status,
+		Timestamp:   strconv.FormatInt(testBase.Add(time.Duration(seconds)*time.Second).Unix(), 10),
+		RecipientID: "919876543210",
+	}
+}
+
+func TestLedgerStatusOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []*whatsappTY.MessageStatus
+		wantStatus string
+		wantTimes  map[string]int // status: seconds from the base time
+	}{
+		{
+			name:       "in order",
+			statuses:   []*whatsappTY.MessageStatus{testStatus("sent", 1), testStatus("delivered", 2), testStatus("read", 3)},
+			wantStatus: whatsappTY.MESSAGE_STATUS_READ,
+			wantTimes:  map[string]int{"sent": 1, "delivered": 2, "read": 3},
+		},
+		{
+			name:       "read before delivered",
+			statuses:   []*whatsappTY.MessageStatus{testStatus("sent", 1), testStatus("read", 3), testStatus("delivered", 2)},
+			wantStatus: whatsappTY.MESSAGE_STATUS_READ,
+			wantTimes:  map[string]int{"sent": 1, "delivered": 2, "read": 3},
+		},
+		{
+			name:       "duplicate keeps the first time",
+			statuses:   []*whatsappTY.MessageStatus{testStatus("delivered", 5), testStatus("delivered", 2)},
+			wantStatus: whatsappTY.MESSAGE_STATUS_DELIVERED,
+			wantTimes:  map[string]int{"delivered": 2},
+		},
+		{
+			name:       "failed after sent",
+			statuses:   []*whatsappTY.MessageStatus{testStatus("sent", 1), testStatus("failed", 2), testStatus("delivered", 3)},
+			wantStatus: whatsappTY.MESSAGE_STATUS_FAILED,
+			wantTimes:  map[string]int{"sent": 1, "failed": 2, "delivered": 3},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledger := New(context.TODO(), nil)
+			for _, status := range tc.statuses {
+				if err := ledger.UpdateStatus(context.TODO(), "phone-1", status); err != nil {
+					t.Fatal(err)
+				}
+			}
+			record, err := ledger.Get(context.TODO(), "wamid-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if record == nil {
+				t.Fatal("record not found")
+			}
+			if record.Status != tc.wantStatus {
+				t.Errorf("status = %s, want %s", record.Status, tc.wantStatus)
+			}
+			if len(record.StatusTimes) != len(tc.wantTimes) {
+				t.Errorf("status times = %v, want %d entries", record.StatusTimes, len(tc.wantTimes))
+			}
+			for status, seconds := range tc.wantTimes {
+				want := testBase.Add(time.Duration(seconds) * time.Second)
+				if got := record.Time(status); !got.Equal(want) {
+					t.Errorf("%s time = %v, want %v", status, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLedgerRecord(t *testing.T) {
+	ledger := New(context.TODO(), nil)
+	// status received before the message is recorded
+	if err := ledger.UpdateStatus(context.TODO(), "phone-1", testStatus("delivered", 2)); err != nil {
+		t.Fatal(err)
+	}
+	message := whatsappTY.Message{To: "+91 98765 43210", Type: whatsappTY.MESSAGE_TYPE_TEMPLATE, Template: &whatsappTY.MessageTemplateObject{Name: "welcome"}}
+	response := &whatsappTY.MessageResponse{Messages: []whatsappTY.MessageResponseMessage{{ID: "wamid-1"}}}
+	if err := ledger.Record(context.TODO(), "phone-1", message, response); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ledger.Query(context.TODO(), Query{Recipient: "+91 98765 43210"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("records = %d, want 1", len(records))
+	}
+	record := records[0]
+	if record.Status != whatsappTY.MESSAGE_STATUS_DELIVERED {
+		t.Errorf("status = %s, want delivered", record.Status)
+	}
+	if record.TemplateName != "welcome" || record.SentAt.IsZero() {
+		t.Errorf("message fields not recorded: %+v", record)
+	}
+	if record.Time(whatsappTY.MESSAGE_STATUS_ACCEPTED).IsZero() {
+		t.Error("accepted time not recorded")
+	}
+}
+
+func TestLedgerConcurrentReads(t *testing.T) {
+	ledger := New(context.TODO(), nil)
+	if err := ledger.UpdateStatus(context.TODO(), "phone-1", testStatus("sent", 0)); err != nil {
+		t.Fatal(err)
+	}
+	record, err := ledger.Get(context.TODO(), "wamid-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for index := 1; index <= 200; index++ {
+			status := testStatus("delivered", index)
+			status.Status = "status-" + strconv.Itoa(index)
+			_ = ledger.UpdateStatus(context.TODO(), "phone-1", status)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for index := 0; index < 200; index++ {
+			// the returned records are not changed by the updates
+			_, _ = json.Marshal(record)
+			_ = record.createdAt()
+			if _, err := ledger.Query(context.TODO(), Query{From: testBase}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if len(record.StatusTimes) != 1 {
+		t.Errorf("returned record changed by the updates, status times = %d", len(record.StatusTimes))
+	}
+}
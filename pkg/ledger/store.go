@@ -0,0 +1,151 @@
+package ledger
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// Record is an outbound message and the delivery status
+type Record struct {
+	MessageID             string                         `json:"message_id"` // wamid
+	PhoneNumberID         string                         `json:"phone_number_id,omitempty"`
+	Recipient             string                         `json:"recipient,omitempty"` // wa_id
+	Type                  string                         `json:"type,omitempty"`
+	TemplateName          string                         `json:"template_name,omitempty"`
+	BizOpaqueCallbackData string                         `json:"biz_opaque_callback_data,omitempty"`
+	Status                string                         `json:"status,omitempty"` // options: accepted, held_for_quality_assessment, sent, delivered, read, failed
+	StatusTimes           map[string]time.Time           `json:"status_times,omitempty"`
+	Errors                []whatsappTY.NotificationError `json:"errors,omitempty"`  // failed status errors
+	SentAt                time.Time                      `json:"sent_at,omitempty"` // posted time, zero if the message was not posted by this client
+	UpdatedAt             time.Time                      `json:"updated_at,omitempty"`
+}
+
+// Time returns the time of the reached status, zero if not available
+func (r *Record) Time(status string) time.Time {
+	return r.StatusTimes[status]
+}
+
+// returns a deep copy, the status times and errors are not shared with the record
+func (r *Record) clone() Record {
+	cloned := *r
+	if r.StatusTimes != nil {
+		cloned.StatusTimes = make(map[string]time.Time, len(r.StatusTimes))
+		for status, statusTime := range r.StatusTimes {
+			cloned.StatusTimes[status] = statusTime
+		}
+	}
+	if r.Errors != nil {
+		cloned.Errors = append([]whatsappTY.NotificationError{}, r.Errors...)
+	}
+	return cloned
+}
+
+// returns the reference time of the record, used in the time range queries
+func (r *Record) createdAt() time.Time {
+	if !r.SentAt.IsZero() {
+		return r.SentAt
+	}
+	first := time.Time{}
+	for _, statusTime := range r.StatusTimes {
+		if first.IsZero() || statusTime.Before(first) {
+			first = statusTime
+		}
+	}
+	return first
+}
+
+// Query filters the records, empty fields are not filtered
+type Query struct {
+	PhoneNumberID string
+	Recipient     string
+	Statuses      []string
+	From          time.Time // inclusive, on the sent time
+	To            time.Time // exclusive, on the sent time
+	Limit         int
+}
+
+// matches reports the record matches the query
+func (q *Query) matches(record *Record) bool {
+	if q.PhoneNumberID != "" && record.PhoneNumberID != q.PhoneNumberID {
+		return false
+	}
+	if q.Recipient != "" && record.Recipient != q.Recipient {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, status := range q.Statuses {
+			if record.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	createdAt := record.createdAt()
+	if !q.From.IsZero() && createdAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !createdAt.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// Store keeps the records, Get returns nil if the record is not available
+type Store interface {
+	Get(ctx context.Context, messageID string) (*Record, error)
+	Save(ctx context.Context, record Record) error
+	// Query returns the matching records, ordered by the sent time
+	Query(ctx context.Context, query Query) ([]Record, error)
+}
+
+// MemoryStore keeps the records in memory, the records are copied in and out
+type MemoryStore struct {
+	mutex   sync.RWMutex
+	records map[string]Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+func (ms *MemoryStore) Get(ctx context.Context, messageID string) (*Record, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	record, found := ms.records[messageID]
+	if !found {
+		return nil, nil
+	}
+	cloned := record.clone()
+	return &cloned, nil
+}
+
+func (ms *MemoryStore) Save(ctx context.Context, record Record) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.records[record.MessageID] = record.clone()
+	return nil
+}
+
+func (ms *MemoryStore) Query(ctx context.Context, query Query) ([]Record, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	records := []Record{}
+	for _, record := range ms.records {
+		if query.matches(&record) {
+			records = append(records, record.clone())
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].createdAt().Before(records[j].createdAt()) })
+	if query.Limit > 0 && len(records) > query.Limit {
+		records = records[:query.Limit]
+	}
+	return records, nil
+}
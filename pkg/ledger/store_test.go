@@ -0,0 +1,88 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+func TestMemoryStoreCopies(t *testing.T) {
+	ctx := context.TODO()
+	store := NewMemoryStore()
+	record := Record{
+		MessageID:   "wamid-1",
+		Status:      whatsappTY.MESSAGE_STATUS_SENT,
+		StatusTimes: map[string]time.Time{whatsappTY.MESSAGE_STATUS_SENT: testBase},
+		Errors:      []whatsappTY.NotificationError{{Code: 1}},
+		SentAt:      testBase,
+	}
+	if err := store.Save(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	// changes on the saved value
+	record.StatusTimes["saved"] = testBase
+	record.Errors[0].Code = 2
+
+	got, err := store.Get(ctx, "wamid-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.StatusTimes["get"] = testBase
+	got.Errors[0].Code = 3
+
+	queried, err := store.Query(ctx, Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queried) != 1 {
+		t.Fatalf("records = %d, want 1", len(queried))
+	}
+	queried[0].StatusTimes["query"] = testBase
+
+	stored, err := store.Get(ctx, "wamid-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.StatusTimes) != 1 {
+		t.Errorf("stored status times changed: %v", stored.StatusTimes)
+	}
+	if stored.Errors[0].Code != 1 {
+		t.Errorf("stored error code = %d, want 1", stored.Errors[0].Code)
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	record := Record{
+		MessageID:     "wamid-1",
+		PhoneNumberID: "phone-1",
+		Recipient:     "919876543210",
+		Status:        whatsappTY.MESSAGE_STATUS_DELIVERED,
+		SentAt:        testBase,
+	}
+	statusOnly := Record{MessageID: "wamid-2", StatusTimes: map[string]time.Time{"sent": testBase.Add(time.Minute), "delivered": testBase.Add(2 * time.Minute)}}
+
+	tests := []struct {
+		name   string
+		record Record
+		query  Query
+		want   bool
+	}{
+		{name: "empty query", record: record, query: Query{}, want: true},
+		{name: "phone number", record: record, query: Query{PhoneNumberID: "phone-2"}, want: false},
+		{name: "recipient", record: record, query: Query{Recipient: "919876543210"}, want: true},
+		{name: "status", record: record, query: Query{Statuses: []string{"read", "delivered"}}, want: true},
+		{name: "other status", record: record, query: Query{Statuses: []string{"failed"}}, want: false},
+		{name: "from inclusive", record: record, query: Query{From: testBase}, want: true},
+		{name: "to exclusive", record: record, query: Query{To: testBase}, want: false},
+		{name: "first status time without sent time", record: statusOnly, query: Query{From: testBase.Add(time.Minute), To: testBase.Add(2 * time.Minute)}, want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.query.matches(&tc.record); got != tc.want {
+				t.Errorf("matches = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	messageAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/message"
+	"github.com/jkandasa/whatsapp-cloud-api/pkg/session"
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// defaults of the queue configuration
+const (
+	DefaultWorkers      = 4
+	DefaultMaxAttempts  = 5
+	DefaultPollInterval = time.Second
+	DefaultBaseDelay    = 5 * time.Second
+	DefaultMaxDelay     = 10 * time.Minute
+	DefaultRetention    = 7 * 24 * time.Hour
+
+	// finished jobs are purged at most on this interval
+	maxPurgeInterval = time.Hour
+
+	// a claimed job is due again after the lease, if the worker did not update it
+	claimLease = 5 * time.Minute
+)
+
+// ErrQueueStarted returned when the queue is started twice
+var ErrQueueStarted = errors.New("queue already started")
+
+// Sender posts the message, MessageAPI implements it
+type Sender interface {
+	Post(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error)
+}
+
+// SenderFunc adapts a function to the Sender
+type SenderFunc func(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error)
+
+func (sf SenderFunc) Post(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+	return sf(ctx, message)
+}
+
+// JobHook is called on the job state changes
+type JobHook func(ctx context.Context, job Job)
+
+// Queue sends the enqueued messages with a pool of workers.
+// failed messages are retried with backoff and dead-lettered after the max attempts.
+// the delivery is at-least-once, a message in flight on a crash is sent again on restart
+type Queue struct {
+	logger       *zap.Logger
+	sender       Sender
+	store        Store
+	workers      int
+	maxAttempts  int
+	pollInterval time.Duration
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	retention    time.Duration // zero disables the purge
+
+	hooksMutex  sync.RWMutex
+	sentHooks   []JobHook
+	deadHooks   []JobHook
+	mutex       sync.Mutex
+	inflight    map[string]bool
+	wakeup      chan struct{}
+	cancel      context.CancelFunc
+	stopped     chan struct{}
+	randomMutex sync.Mutex
+	random      *mathrand.Rand
+}
+
+// New returns a queue, the store is created from the config if nil
+// (file store if the path is set, otherwise memory store)
+func New(ctx context.Context, sender Sender, store Store, cfg types.QueueConfig) (*Queue, error) {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+
+	if store == nil {
+		if cfg.Path != "" {
+			fileStore, err := NewFileStore(cfg.Path)
+			if err != nil {
+				return nil, err
+			}
+			store = fileStore
+		} else {
+			store = NewMemoryStore()
+		}
+	}
+
+	q := &Queue{
+		logger:       logger.Named("message_queue"),
+		sender:       sender,
+		store:        store,
+		workers:      cfg.Workers,
+		maxAttempts:  cfg.MaxAttempts,
+		pollInterval: cfg.PollInterval,
+		baseDelay:    cfg.BaseDelay,
+		maxDelay:     cfg.MaxDelay,
+		retention:    cfg.Retention,
+		inflight:     map[string]bool{},
+		wakeup:       make(chan struct{}, 1),
+		random:       mathrand.New(mathrand.NewSource(time.Now().UnixNano())),
+	}
+	if q.workers <= 0 {
+		q.workers = DefaultWorkers
+	}
+	if q.maxAttempts <= 0 {
+		q.maxAttempts = DefaultMaxAttempts
+	}
+	if q.pollInterval <= 0 {
+		q.pollInterval = DefaultPollInterval
+	}
+	if q.baseDelay <= 0 {
+		q.baseDelay = DefaultBaseDelay
+	}
+	if q.maxDelay <= 0 {
+		q.maxDelay = DefaultMaxDelay
+	}
+	if q.retention == 0 {
+		q.retention = DefaultRetention
+	} else if q.retention < 0 {
+		q.retention = 0
+	}
+	return q, nil
+}
+
+// OnSent registers a hook, called when the message is sent and the wamid is assigned
+func (q *Queue) OnSent(hook JobHook) {
+	q.hooksMutex.Lock()
+	defer q.hooksMutex.Unlock()
+	q.sentHooks = append(q.sentHooks, hook)
+}
+
+// OnDeadLetter registers a hook, called when the message is moved to the dead letters
+func (q *Queue) OnDeadLetter(hook JobHook) {
+	q.hooksMutex.Lock()
+	defer q.hooksMutex.Unlock()
+	q.deadHooks = append(q.deadHooks, hook)
+}
+
+func (q *Queue) runHooks(ctx context.Context, hooks []JobHook, job Job) {
+	logger := loggerUtils.FromContextOrDefault(ctx, q.logger)
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("panic on job hook", zap.String("jobId", job.ID), zap.Any("panic", r))
+				}
+			}()
+			hook(ctx, job)
+		}()
+	}
+}
+
+func newJobID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Enqueue adds the message to the queue, the key is the idempotency key.
+// if a job with the key exists, the stored job is returned and the message is not added again.
+// empty key generates a random key. zero sendAt sends the message immediately
+func (q *Queue) Enqueue(ctx context.Context, key string, message whatsappTY.Message, sendAt time.Time) (*Job, error) {
+	if key == "" {
+		key = newJobID()
+	}
+	now := time.Now()
+	nextAttemptAt := now
+	if sendAt.After(now) {
+		nextAttemptAt = sendAt
+	}
+	job := Job{
+		ID:            key,
+		Message:       message,
+		State:         JobStatePending,
+		SendAt:        sendAt,
+		NextAttemptAt: nextAttemptAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	stored, added, err := q.store.Add(ctx, job)
+	if err != nil {
+		return nil, fmt.Errorf("error on adding job: %w", err)
+	}
+	if added {
+		q.notify()
+	}
+	return stored, nil
+}
+
+// Get returns the job, nil if not available
+func (q *Queue) Get(ctx context.Context, id string) (*Job, error) {
+	return q.store.Get(ctx, id)
+}
+
+// DeadLetters returns the dead-lettered jobs
+func (q *Queue) DeadLetters(ctx context.Context) ([]Job, error) {
+	return q.store.List(ctx, JobStateDead)
+}
+
+// Retry moves the dead-lettered job back to the queue, with the attempts reset
+func (q *Queue) Retry(ctx context.Context, id string) error {
+	job, err := q.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("job not found:%s", id)
+	}
+	if job.State != JobStateDead {
+		return fmt.Errorf("job is not dead-lettered:%s, state:%s", id, job.State)
+	}
+	job.State = JobStatePending
+	job.Attempts = 0
+	job.NextAttemptAt = time.Now()
+	job.UpdatedAt = job.NextAttemptAt
+	err = q.store.Update(ctx, *job)
+	if err != nil {
+		return err
+	}
+	q.notify()
+	return nil
+}
+
+// Purge removes the finished (sent and dead) jobs updated before the given time.
+// removed keys are accepted again by Enqueue
+func (q *Queue) Purge(ctx context.Context, before time.Time) (int, error) {
+	return q.store.Purge(ctx, before)
+}
+
+// wakes up the dispatcher, without waiting for the poll interval
+func (q *Queue) notify() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Start starts the dispatcher and the workers, runs until Stop is called or the context is done
+func (q *Queue) Start(ctx context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.cancel != nil {
+		return ErrQueueStarted
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+	q.stopped = make(chan struct{})
+
+	jobs := make(chan Job)
+	wg := sync.WaitGroup{}
+	for index := 0; index < q.workers; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				q.process(ctx, job)
+			}
+		}()
+	}
+
+	go func() {
+		q.dispatch(ctx, jobs)
+		close(jobs)
+		wg.Wait()
+		close(q.stopped)
+	}()
+
+	q.logger.Debug("queue started", zap.Int("workers", q.workers))
+	return nil
+}
+
+// Stop stops the queue, waits for the messages in flight
+func (q *Queue) Stop() {
+	q.mutex.Lock()
+	cancel, stopped := q.cancel, q.stopped
+	q.cancel = nil
+	q.mutex.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-stopped
+	q.logger.Debug("queue stopped")
+}
+
+// feeds the due jobs to the workers
+func (q *Queue) dispatch(ctx context.Context, jobs chan<- Job) {
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	purgedAt := time.Time{}
+	for {
+		if q.retention > 0 && time.Since(purgedAt) >= min(q.retention, maxPurgeInterval) {
+			q.purgeFinished(ctx)
+			purgedAt = time.Now()
+		}
+
+		due, err := q.store.Due(ctx, time.Now(), q.workers*2)
+		if err != nil {
+			q.logger.Error("error on getting due jobs", zap.Error(err))
+		}
+		for _, job := range due {
+			if !q.markInflight(job.ID) {
+				continue
+			}
+			// the snapshot can be stale, the job could be sent by a worker already
+			claimed, err := q.store.Claim(ctx, job.ID, time.Now(), claimLease)
+			if err != nil || claimed == nil {
+				if err != nil {
+					q.logger.Error("error on claiming job", zap.String("jobId", job.ID), zap.Error(err))
+				}
+				q.unmarkInflight(job.ID)
+				continue
+			}
+			select {
+			case jobs <- *claimed:
+			case <-ctx.Done():
+				q.release(ctx, *claimed)
+				q.unmarkInflight(job.ID)
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wakeup:
+		}
+	}
+}
+
+// removes the finished jobs older than the retention
+func (q *Queue) purgeFinished(ctx context.Context) {
+	count, err := q.store.Purge(ctx, time.Now().Add(-q.retention))
+	if err != nil {
+		q.logger.Error("error on purging finished jobs", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		q.logger.Debug("finished jobs purged", zap.Int("count", count))
+	}
+}
+
+func (q *Queue) markInflight(id string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.inflight[id] {
+		return false
+	}
+	q.inflight[id] = true
+	return true
+}
+
+func (q *Queue) unmarkInflight(id string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.inflight, id)
+}
+
+// returns the claimed job to the store unchanged, due again without waiting for the lease
+func (q *Queue) release(ctx context.Context, job Job) {
+	if err := q.store.Update(context.WithoutCancel(ctx), job); err != nil {
+		q.logger.Error("error on releasing job", zap.String("jobId", job.ID), zap.Error(err))
+	}
+}
+
+// sends the job and updates the state
+func (q *Queue) process(ctx context.Context, job Job) {
+	defer q.unmarkInflight(job.ID)
+	logger := loggerUtils.FromContextOrDefault(ctx, q.logger)
+
+	response, err := q.sender.Post(ctx, job.Message)
+	if err != nil && ctx.Err() != nil {
+		// stopped, sent again on the next start
+		q.release(ctx, job)
+		return
+	}
+
+	now := time.Now()
+	job.UpdatedAt = now
+
+	var hooks []JobHook
+	switch {
+	case err == nil:
+		job.Attempts++
+		job.State = JobStateSent
+		job.MessageID = response.MessageID()
+		job.LastError = ""
+		q.hooksMutex.RLock()
+		hooks = q.sentHooks
+		q.hooksMutex.RUnlock()
+		logger.Debug("message sent", zap.String("jobId", job.ID), zap.String("messageId", job.MessageID))
+
+	case errors.Is(err, customClient.ErrWouldThrottle):
+		// local rate limit, not counted as an attempt
+		job.NextAttemptAt = now.Add(q.pollInterval)
+
+	default:
+		job.Attempts++
+		job.LastError = err.Error()
+		if !isRetryable(err) || job.Attempts >= q.maxAttempts {
+			job.State = JobStateDead
+			q.hooksMutex.RLock()
+			hooks = q.deadHooks
+			q.hooksMutex.RUnlock()
+			logger.Warn("message moved to dead letters", zap.String("jobId", job.ID), zap.Int("attempts", job.Attempts), zap.Error(err))
+		} else {
+			job.NextAttemptAt = now.Add(q.delay(job.Attempts, err))
+			logger.Debug("message send failed, retrying", zap.String("jobId", job.ID), zap.Int("attempts", job.Attempts), zap.Time("nextAttemptAt", job.NextAttemptAt), zap.Error(err))
+		}
+	}
+
+	if err := q.store.Update(ctx, job); err != nil {
+		logger.Error("error on updating job", zap.String("jobId", job.ID), zap.String("state", job.State), zap.Error(err))
+	}
+	q.runHooks(ctx, hooks, job)
+}
+
+// reports the failure can be recovered by sending again.
+// rejected and invalid messages fail the same way on each attempt
+func isRetryable(err error) bool {
+	var validationErr *whatsappTY.ValidationError
+	if errors.As(err, &validationErr) {
+		return false
+	}
+	if errors.Is(err, messageAPI.ErrGuardRejected) || errors.Is(err, session.ErrWindowClosed) {
+		return false
+	}
+	if _, ok := customClient.AsGraphError(err); !ok {
+		// network failures
+		return true
+	}
+	return customClient.IsTemporary(err) || customClient.IsRateLimited(err)
+}
+
+// returns the backoff delay of the attempt, retry after of the api takes precedence
+func (q *Queue) delay(attempt int, err error) time.Duration {
+	if graphErr, ok := customClient.AsGraphError(err); ok && graphErr.RetryAfter > 0 {
+		return min(graphErr.RetryAfter, q.maxDelay)
+	}
+	delay := float64(q.baseDelay) * math.Pow(2, float64(attempt-1))
+	if delay > float64(q.maxDelay) {
+		delay = float64(q.maxDelay)
+	}
+	// 20% jitter
+	q.randomMutex.Lock()
+	jitter := (q.random.Float64()*2 - 1) * 0.2
+	q.randomMutex.Unlock()
+	return time.Duration(delay * (1 + jitter))
+}
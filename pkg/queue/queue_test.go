@@ -0,0 +1,239 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	messageAPI "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/message"
+	"github.com/jkandasa/whatsapp-cloud-api/pkg/session"
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+func TestIsRetryable(t *testing.T) {
+	validationErr := errors.Join(&whatsappTY.ValidationError{Field: "to", Reason: "required"})
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "network failure", err: errors.New("connection reset by peer"), want: true},
+		{name: "server error", err: &customClient.GraphError{HTTPStatus: http.StatusInternalServerError}, want: true},
+		{name: "rate limited", err: &customClient.GraphError{HTTPStatus: http.StatusBadRequest, Code: customClient.ErrorCodeRateLimitHit}, want: true},
+		{name: "bad request", err: &customClient.GraphError{HTTPStatus: http.StatusBadRequest, Code: 100}, want: false},
+		{name: "invalid message", err: fmt.Errorf("invalid message: %w", validationErr), want: false},
+		{name: "window closed", err: fmt.Errorf("%w, to:123", session.ErrWindowClosed), want: false},
+		{name: "guard rejected", err: fmt.Errorf("%w: %w", messageAPI.ErrGuardRejected, errors.New("blocked recipient")), want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryable(tc.err); got != tc.want {
+				t.Errorf("retryable = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// returns a started queue with short delays, stopped on cleanup
+func newTestQueue(t *testing.T, sender SenderFunc, maxAttempts int) *Queue {
+	t.Helper()
+	q, err := New(context.TODO(), sender, NewMemoryStore(), types.QueueConfig{
+		Workers:      4,
+		MaxAttempts:  maxAttempts,
+		PollInterval: 5 * time.Millisecond,
+		BaseDelay:    time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(q.Stop)
+	return q
+}
+
+// waits for the job to reach the state
+func waitForState(t *testing.T, q *Queue, id, state string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(context.TODO(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job != nil && job.State == state {
+			return *job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach the state %s", id, state)
+	return Job{}
+}
+
+func sentResponse(id string) *whatsappTY.MessageResponse {
+	return &whatsappTY.MessageResponse{Messages: []whatsappTY.MessageResponseMessage{{ID: id}}}
+}
+
+func TestQueueStates(t *testing.T) {
+	temporary := &customClient.GraphError{HTTPStatus: http.StatusServiceUnavailable}
+	permanent := &customClient.GraphError{HTTPStatus: http.StatusBadRequest, Code: 100}
+
+	tests := []struct {
+		name         string
+		results      []error // result per send, the last one repeats
+		maxAttempts  int
+		wantState    string
+		wantAttempts int
+		wantSends    int
+	}{
+		{name: "sent", results: []error{nil}, maxAttempts: 3, wantState: JobStateSent, wantAttempts: 1, wantSends: 1},
+		{name: "sent after retries", results: []error{temporary, temporary, nil}, maxAttempts: 3, wantState: JobStateSent, wantAttempts: 3, wantSends: 3},
+		{name: "dead after max attempts", results: []error{temporary}, maxAttempts: 3, wantState: JobStateDead, wantAttempts: 3, wantSends: 3},
+		{name: "permanent error dead at once", results: []error{permanent}, maxAttempts: 3, wantState: JobStateDead, wantAttempts: 1, wantSends: 1},
+		{name: "window closed dead at once", results: []error{fmt.Errorf("%w: %w", messageAPI.ErrGuardRejected, session.ErrWindowClosed)}, maxAttempts: 3, wantState: JobStateDead, wantAttempts: 1, wantSends: 1},
+		{name: "local throttle not counted", results: []error{customClient.ErrWouldThrottle, customClient.ErrWouldThrottle, nil}, maxAttempts: 1, wantState: JobStateSent, wantAttempts: 1, wantSends: 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sends := int32(0)
+			q := newTestQueue(t, func(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+				index := int(atomic.AddInt32(&sends, 1)) - 1
+				err := tc.results[min(index, len(tc.results)-1)]
+				if err != nil {
+					return nil, err
+				}
+				return sentResponse("wamid-1"), nil
+			}, tc.maxAttempts)
+
+			hooks := make(chan string, 2)
+			q.OnSent(func(ctx context.Context, job Job) { hooks <- JobStateSent })
+			q.OnDeadLetter(func(ctx context.Context, job Job) { hooks <- JobStateDead })
+
+			if _, err := q.Enqueue(context.TODO(), "job-1", whatsappTY.Message{To: "123"}, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+			job := waitForState(t, q, "job-1", tc.wantState)
+			if job.Attempts != tc.wantAttempts {
+				t.Errorf("attempts = %d, want %d", job.Attempts, tc.wantAttempts)
+			}
+			if got := int(atomic.LoadInt32(&sends)); got != tc.wantSends {
+				t.Errorf("sends = %d, want %d", got, tc.wantSends)
+			}
+			if tc.wantState == JobStateSent && job.MessageID != "wamid-1" {
+				t.Errorf("message id = %s, want wamid-1", job.MessageID)
+			}
+			select {
+			case state := <-hooks:
+				if state != tc.wantState {
+					t.Errorf("hook state = %s, want %s", state, tc.wantState)
+				}
+			case <-time.After(time.Second):
+				t.Error("hook not called")
+			}
+		})
+	}
+}
+
+func TestQueueSendsOnce(t *testing.T) {
+	mutex := sync.Mutex{}
+	sends := map[string]int{}
+	q := newTestQueue(t, func(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+		mutex.Lock()
+		sends[message.To]++
+		mutex.Unlock()
+		// slow sender, the due snapshots see the job in flight
+		time.Sleep(10 * time.Millisecond)
+		return sentResponse("wamid-" + message.To), nil
+	}, 3)
+
+	count := 20
+	for index := 0; index < count; index++ {
+		to := fmt.Sprintf("%d", index)
+		if _, err := q.Enqueue(context.TODO(), "job-"+to, whatsappTY.Message{To: to}, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// same idempotency key is not added again
+	if _, err := q.Enqueue(context.TODO(), "job-0", whatsappTY.Message{To: "0"}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	for index := 0; index < count; index++ {
+		waitForState(t, q, fmt.Sprintf("job-%d", index), JobStateSent)
+	}
+	// waits a few polls for the duplicates
+	time.Sleep(50 * time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for to, sent := range sends {
+		if sent != 1 {
+			t.Errorf("message to %s sent %d times", to, sent)
+		}
+	}
+}
+
+func TestQueueRetryDeadLetter(t *testing.T) {
+	fail := int32(1)
+	q := newTestQueue(t, func(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+		if atomic.LoadInt32(&fail) == 1 {
+			return nil, &customClient.GraphError{HTTPStatus: http.StatusBadRequest, Code: 100}
+		}
+		return sentResponse("wamid-1"), nil
+	}, 3)
+
+	if _, err := q.Enqueue(context.TODO(), "job-1", whatsappTY.Message{To: "123"}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, q, "job-1", JobStateDead)
+
+	deadLetters, err := q.DeadLetters(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(deadLetters))
+	}
+
+	atomic.StoreInt32(&fail, 0)
+	if err := q.Retry(context.TODO(), "job-1"); err != nil {
+		t.Fatal(err)
+	}
+	job := waitForState(t, q, "job-1", JobStateSent)
+	if job.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1 after the reset", job.Attempts)
+	}
+	if err := q.Retry(context.TODO(), "job-1"); err == nil {
+		t.Error("sent job moved back to the queue")
+	}
+}
+
+func TestQueueScheduled(t *testing.T) {
+	sends := int32(0)
+	q := newTestQueue(t, func(ctx context.Context, message whatsappTY.Message) (*whatsappTY.MessageResponse, error) {
+		atomic.AddInt32(&sends, 1)
+		return sentResponse("wamid-1"), nil
+	}, 3)
+
+	sendAt := time.Now().Add(100 * time.Millisecond)
+	if _, err := q.Enqueue(context.TODO(), "job-1", whatsappTY.Message{To: "123"}, sendAt); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&sends); got != 0 {
+		t.Fatalf("sent %d times before the schedule", got)
+	}
+	job := waitForState(t, q, "job-1", JobStateSent)
+	if job.UpdatedAt.Before(sendAt) {
+		t.Errorf("sent at %v, before the schedule %v", job.UpdatedAt, sendAt)
+	}
+}
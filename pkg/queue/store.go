@@ -0,0 +1,274 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	fileUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/file"
+)
+
+// job states
+const (
+	JobStatePending = "pending"
+	JobStateSent    = "sent"
+	JobStateDead    = "dead" // dead letter, failed after the max attempts or with a permanent error
+)
+
+// Job is an outbound message in the queue
+type Job struct {
+	ID            string             `json:"id"` // idempotency key
+	Message       whatsappTY.Message `json:"message"`
+	State         string             `json:"state"`
+	SendAt        time.Time          `json:"send_at,omitempty"` // not sent before
+	NextAttemptAt time.Time          `json:"next_attempt_at"`
+	Attempts      int                `json:"attempts"`
+	LastError     string             `json:"last_error,omitempty"`
+	MessageID     string             `json:"message_id,omitempty"` // wamid, assigned on sent
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// Store keeps the jobs durably
+type Store interface {
+	// Add adds the job, returns the stored job and false if the id exists already
+	Add(ctx context.Context, job Job) (*Job, bool, error)
+	// Get returns the job, nil if not available
+	Get(ctx context.Context, id string) (*Job, error)
+	Update(ctx context.Context, job Job) error
+	Delete(ctx context.Context, id string) error
+	// List returns the jobs in the state, ordered by the created time
+	List(ctx context.Context, state string) ([]Job, error)
+	// Due returns the pending jobs, ready to be sent at the given time, ordered by the next attempt time
+	Due(ctx context.Context, now time.Time, limit int) ([]Job, error)
+	// Claim atomically takes the job for sending, if it is still pending and due at the given time.
+	// the next attempt time is moved to the lease end, the job is due again if not updated before.
+	// returns the stored job, nil if the job is not available for sending
+	Claim(ctx context.Context, id string, now time.Time, lease time.Duration) (*Job, error)
+	// Purge removes the finished (sent and dead) jobs updated before the given time, returns the removed count
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+// MemoryStore keeps the jobs in memory
+type MemoryStore struct {
+	mutex sync.RWMutex
+	jobs  map[string]Job
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: map[string]Job{}}
+}
+
+func (ms *MemoryStore) Add(ctx context.Context, job Job) (*Job, bool, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.add(job)
+}
+
+func (ms *MemoryStore) add(job Job) (*Job, bool, error) {
+	if existing, found := ms.jobs[job.ID]; found {
+		return &existing, false, nil
+	}
+	ms.jobs[job.ID] = job
+	return &job, true, nil
+}
+
+func (ms *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	job, found := ms.jobs[id]
+	if !found {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (ms *MemoryStore) Update(ctx context.Context, job Job) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.update(job)
+}
+
+func (ms *MemoryStore) update(job Job) error {
+	if _, found := ms.jobs[job.ID]; !found {
+		return fmt.Errorf("job not found:%s", job.ID)
+	}
+	ms.jobs[job.ID] = job
+	return nil
+}
+
+func (ms *MemoryStore) Delete(ctx context.Context, id string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	delete(ms.jobs, id)
+	return nil
+}
+
+func (ms *MemoryStore) List(ctx context.Context, state string) ([]Job, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	jobs := []Job{}
+	for _, job := range ms.jobs {
+		if state == "" || job.State == state {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+func (ms *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]Job, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	jobs := []Job{}
+	for _, job := range ms.jobs {
+		if job.State == JobStatePending && !job.NextAttemptAt.After(now) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].NextAttemptAt.Before(jobs[j].NextAttemptAt) })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func (ms *MemoryStore) Claim(ctx context.Context, id string, now time.Time, lease time.Duration) (*Job, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	job, found := ms.jobs[id]
+	if !found || job.State != JobStatePending || job.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+	claimed := job
+	job.NextAttemptAt = now.Add(lease)
+	ms.jobs[id] = job
+	return &claimed, nil
+}
+
+func (ms *MemoryStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.purge(before), nil
+}
+
+func (ms *MemoryStore) purge(before time.Time) int {
+	count := 0
+	for id, job := range ms.jobs {
+		if job.State != JobStatePending && job.UpdatedAt.Before(before) {
+			delete(ms.jobs, id)
+			count++
+		}
+	}
+	return count
+}
+
+// FileStore keeps the jobs in memory and persists them in a json file on each change.
+// the whole file is rewritten on each change, suitable for moderate queue sizes.
+// finished jobs should be purged, the queue purges them after the retention
+type FileStore struct {
+	path  string
+	store *MemoryStore
+}
+
+// NewFileStore returns a file store, loads the jobs from the file if available
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{path: path, store: NewMemoryStore()}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fs, nil
+		}
+		return nil, err
+	}
+	jobs := []Job{}
+	err = json.Unmarshal(data, &jobs)
+	if err != nil {
+		return nil, fmt.Errorf("error on decoding queue file[%s]: %w", path, err)
+	}
+	for _, job := range jobs {
+		fs.store.jobs[job.ID] = job
+	}
+	return fs, nil
+}
+
+func (fs *FileStore) Add(ctx context.Context, job Job) (*Job, bool, error) {
+	fs.store.mutex.Lock()
+	defer fs.store.mutex.Unlock()
+	stored, added, err := fs.store.add(job)
+	if err != nil || !added {
+		return stored, added, err
+	}
+	if err := fs.save(); err != nil {
+		delete(fs.store.jobs, job.ID)
+		return nil, false, err
+	}
+	return stored, true, nil
+}
+
+func (fs *FileStore) Get(ctx context.Context, id string) (*Job, error) {
+	return fs.store.Get(ctx, id)
+}
+
+func (fs *FileStore) Update(ctx context.Context, job Job) error {
+	fs.store.mutex.Lock()
+	defer fs.store.mutex.Unlock()
+	if err := fs.store.update(job); err != nil {
+		return err
+	}
+	return fs.save()
+}
+
+func (fs *FileStore) Delete(ctx context.Context, id string) error {
+	fs.store.mutex.Lock()
+	defer fs.store.mutex.Unlock()
+	if _, found := fs.store.jobs[id]; !found {
+		return nil
+	}
+	delete(fs.store.jobs, id)
+	return fs.save()
+}
+
+func (fs *FileStore) List(ctx context.Context, state string) ([]Job, error) {
+	return fs.store.List(ctx, state)
+}
+
+func (fs *FileStore) Due(ctx context.Context, now time.Time, limit int) ([]Job, error) {
+	return fs.store.Due(ctx, now, limit)
+}
+
+// Claim keeps the lease in memory only, a job claimed before a crash is sent again on restart
+func (fs *FileStore) Claim(ctx context.Context, id string, now time.Time, lease time.Duration) (*Job, error) {
+	return fs.store.Claim(ctx, id, now, lease)
+}
+
+func (fs *FileStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	fs.store.mutex.Lock()
+	defer fs.store.mutex.Unlock()
+	count := fs.store.purge(before)
+	if count == 0 {
+		return 0, nil
+	}
+	return count, fs.save()
+}
+
+// writes the jobs into the file, should be called with the lock held
+func (fs *FileStore) save() error {
+	jobs := make([]Job, 0, len(fs.store.jobs))
+	for _, job := range fs.store.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+
+	data, err := json.Marshal(jobs)
+	if err != nil {
+		return err
+	}
+	return fileUtils.WriteAtomic(fs.path, data, 0o644)
+}
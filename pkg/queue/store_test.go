@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func testJob(id, state string, nextAttemptAt time.Time) Job {
+	return Job{ID: id, State: state, NextAttemptAt: nextAttemptAt, CreatedAt: testNow, UpdatedAt: testNow}
+}
+
+func TestStoreClaim(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"file": func(t *testing.T) Store {
+			store, err := NewFileStore(filepath.Join(t.TempDir(), "queue.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store
+		},
+	}
+
+	tests := []struct {
+		name      string
+		job       *Job
+		id        string
+		wantClaim bool
+	}{
+		{name: "pending and due", job: &Job{ID: "a", State: JobStatePending, NextAttemptAt: testNow}, id: "a", wantClaim: true},
+		{name: "not due yet", job: &Job{ID: "a", State: JobStatePending, NextAttemptAt: testNow.Add(time.Second)}, id: "a", wantClaim: false},
+		{name: "already sent", job: &Job{ID: "a", State: JobStateSent, NextAttemptAt: testNow}, id: "a", wantClaim: false},
+		{name: "dead", job: &Job{ID: "a", State: JobStateDead, NextAttemptAt: testNow}, id: "a", wantClaim: false},
+		{name: "not available", id: "a", wantClaim: false},
+	}
+	for storeName, newStore := range stores {
+		for _, tc := range tests {
+			t.Run(storeName+"/"+tc.name, func(t *testing.T) {
+				ctx := context.TODO()
+				store := newStore(t)
+				if tc.job != nil {
+					if _, _, err := store.Add(ctx, *tc.job); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				claimed, err := store.Claim(ctx, tc.id, testNow, time.Minute)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (claimed != nil) != tc.wantClaim {
+					t.Fatalf("claimed = %v, want claim %v", claimed, tc.wantClaim)
+				}
+				if !tc.wantClaim {
+					return
+				}
+				if !claimed.NextAttemptAt.Equal(tc.job.NextAttemptAt) {
+					t.Errorf("claimed job next attempt = %v, want the stored %v", claimed.NextAttemptAt, tc.job.NextAttemptAt)
+				}
+
+				// claimed twice from a stale snapshot
+				again, err := store.Claim(ctx, tc.id, testNow, time.Minute)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again != nil {
+					t.Fatal("job claimed twice")
+				}
+				due, err := store.Due(ctx, testNow.Add(59*time.Second), 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(due) != 0 {
+					t.Errorf("claimed job is due within the lease")
+				}
+				// lease expired
+				if again, _ = store.Claim(ctx, tc.id, testNow.Add(time.Minute), time.Minute); again == nil {
+					t.Error("job not claimed after the lease")
+				}
+			})
+		}
+	}
+}
+
+func TestStorePurge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := testNow.Add(-time.Hour)
+	jobs := []Job{
+		testJob("pending-old", JobStatePending, testNow),
+		testJob("sent-old", JobStateSent, testNow),
+		testJob("dead-old", JobStateDead, testNow),
+		testJob("sent-new", JobStateSent, testNow),
+	}
+	for index := range jobs[:3] {
+		jobs[index].UpdatedAt = old
+	}
+	for _, job := range jobs {
+		if _, _, err := store.Add(context.TODO(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := store.Purge(context.TODO(), testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("purged = %d, want 2", count)
+	}
+
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id    string
+		found bool
+	}{
+		{id: "pending-old", found: true},
+		{id: "sent-old", found: false},
+		{id: "dead-old", found: false},
+		{id: "sent-new", found: true},
+	}
+	for _, tc := range tests {
+		job, err := reloaded.Get(context.TODO(), tc.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (job != nil) != tc.found {
+			t.Errorf("%s found = %v, want %v", tc.id, job != nil, tc.found)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// ErrWindowClosed returned when a free-form message is sent outside the customer service window
+var ErrWindowClosed = errors.New("customer service window is closed")
+
+// Guard checks the customer service window before posting a message, implements the message api guard.
+// template messages are always allowed. free-form messages outside the window are rejected,
+// or replaced with the fallback template if configured
+type Guard struct {
+	tracker  *Tracker
+	fallback *whatsappTY.MessageTemplateObject
+}
+
+// NewGuard returns a guard, nil fallback rejects the messages outside the window
+func NewGuard(tracker *Tracker, fallback *whatsappTY.MessageTemplateObject) *Guard {
+	return &Guard{tracker: tracker, fallback: fallback}
+}
+
+func (g *Guard) Check(ctx context.Context, phoneNumberID string, message whatsappTY.Message) (whatsappTY.Message, error) {
+	if message.Type == whatsappTY.MESSAGE_TYPE_TEMPLATE || message.Template != nil {
+		return message, nil
+	}
+
+	open, err := g.tracker.CanSendFreeForm(ctx, phoneNumberID, message.To)
+	if err != nil {
+		return message, fmt.Errorf("error on checking customer service window: %w", err)
+	}
+	if open {
+		return message, nil
+	}
+
+	if g.fallback == nil {
+		return message, fmt.Errorf("%w, to:%s", ErrWindowClosed, message.To)
+	}
+
+	logger := loggerUtils.FromContextOrDefault(ctx, g.tracker.logger)
+	logger.Debug("customer service window is closed, sending fallback template", zap.String("to", message.To), zap.String("template", g.fallback.Name))
+
+	template := *g.fallback
+	return whatsappTY.Message{
+		MessagingProduct:      message.MessagingProduct,
+		RecipientType:         message.RecipientType,
+		To:                    message.To,
+		Type:                  whatsappTY.MESSAGE_TYPE_TEMPLATE,
+		Template:              &template,
+		BizOpaqueCallbackData: message.BizOpaqueCallbackData,
+	}, nil
+}
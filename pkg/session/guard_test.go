@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// returns an inbound text message received before the given duration
+func inboundMessage(from string, ago time.Duration) *whatsappTY.InboundMessage {
+	return &whatsappTY.InboundMessage{
+		From:      from,
+		Type:      whatsappTY.MESSAGE_TYPE_TEXT,
+		Timestamp: strconv.FormatInt(time.Now().Add(-ago).Unix(), 10),
+	}
+}
+
+func TestGuardCheck(t *testing.T) {
+	fallback := &whatsappTY.MessageTemplateObject{Name: "reopen_conversation"}
+	text := whatsappTY.Message{To: "+91 98765 43210", Type: whatsappTY.MESSAGE_TYPE_TEXT, Text: &whatsappTY.MessageTextObject{Body: "hi"}}
+	template := whatsappTY.Message{To: "919876543210", Type: whatsappTY.MESSAGE_TYPE_TEMPLATE, Template: &whatsappTY.MessageTemplateObject{Name: "welcome"}}
+
+	tests := []struct {
+		name         string
+		inbound      *whatsappTY.InboundMessage
+		fallback     *whatsappTY.MessageTemplateObject
+		message      whatsappTY.Message
+		wantErr      error
+		wantTemplate string // expected template name of the checked message
+	}{
+		{name: "window open", inbound: inboundMessage("919876543210", time.Hour), message: text},
+		{name: "no session", message: text, wantErr: ErrWindowClosed},
+		{name: "window closed", inbound: inboundMessage("919876543210", 25*time.Hour), message: text, wantErr: ErrWindowClosed},
+		{name: "other phone number", inbound: inboundMessage("14155550123", time.Hour), message: text, wantErr: ErrWindowClosed},
+		{name: "template always allowed", message: template, wantTemplate: "welcome"},
+		{name: "fallback template", fallback: fallback, message: text, wantTemplate: "reopen_conversation"},
+		{name: "system message ignored", inbound: &whatsappTY.InboundMessage{From: "919876543210", Type: whatsappTY.MESSAGE_TYPE_SYSTEM}, message: text, wantErr: ErrWindowClosed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := NewTracker(context.TODO(), nil)
+			if err := tracker.Record(context.TODO(), "phone-1", tc.inbound); err != nil {
+				t.Fatal(err)
+			}
+
+			checked, err := NewGuard(tracker, tc.fallback).Check(context.TODO(), "phone-1", tc.message)
+			if !errors.Is(err, tc.wantErr) || (err != nil) != (tc.wantErr != nil) {
+				t.Fatalf("error = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if checked.To != tc.message.To {
+				t.Errorf("to = %s, want %s", checked.To, tc.message.To)
+			}
+			name := ""
+			if checked.Template != nil {
+				name = checked.Template.Name
+			}
+			if name != tc.wantTemplate {
+				t.Errorf("template = %q, want %q", name, tc.wantTemplate)
+			}
+		})
+	}
+}
+
+func TestTrackerKeepsLatest(t *testing.T) {
+	tracker := NewTracker(context.TODO(), nil)
+	messages := []*whatsappTY.InboundMessage{
+		inboundMessage("919876543210", time.Hour),
+		inboundMessage("919876543210", 30*time.Hour), // delivered out of order
+	}
+	for _, message := range messages {
+		if err := tracker.Record(context.TODO(), "phone-1", message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	left, err := tracker.Remaining(context.TODO(), "phone-1", "+91 98765 43210")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left <= 22*time.Hour || left > 23*time.Hour {
+		t.Errorf("remaining = %v, want about 23h", left)
+	}
+}
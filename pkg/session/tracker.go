@@ -0,0 +1,206 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	customClient "github.com/jkandasa/whatsapp-cloud-api/pkg/api/whatsapp/client"
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"github.com/jkandasa/whatsapp-cloud-api/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+// messaging windows
+// https://developers.facebook.com/docs/whatsapp/pricing#customer-service-windows
+const (
+	// free-form messages can be sent within the window, from the last user message
+	CustomerServiceWindow = 24 * time.Hour
+	// messages are free of charge within the window, when the user reached through a free entry point
+	// (click to whatsapp ad or facebook page button)
+	EntryPointWindow = 72 * time.Hour
+)
+
+// Session keeps the last user initiated message of a phone number and a user
+type Session struct {
+	PhoneNumberID string    `json:"phone_number_id"`
+	WaID          string    `json:"wa_id"`
+	LastMessageAt time.Time `json:"last_message_at"`          // last user initiated message
+	EntryPointAt  time.Time `json:"entry_point_at,omitempty"` // last user message through a free entry point
+}
+
+// WindowRemaining returns the remaining time of the customer service window, zero if closed
+func (s *Session) WindowRemaining(now time.Time) time.Duration {
+	return remaining(s.LastMessageAt, CustomerServiceWindow, now)
+}
+
+// EntryPointRemaining returns the remaining time of the free entry point window, zero if closed
+func (s *Session) EntryPointRemaining(now time.Time) time.Duration {
+	return remaining(s.EntryPointAt, EntryPointWindow, now)
+}
+
+func remaining(start time.Time, window time.Duration, now time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	left := start.Add(window).Sub(now)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// Store keeps the sessions, Get returns nil if the session is not available
+type Store interface {
+	Get(ctx context.Context, phoneNumberID, waID string) (*Session, error)
+	Set(ctx context.Context, session Session) error
+}
+
+// MemoryStore keeps the sessions in memory, closed sessions are removed periodically
+type MemoryStore struct {
+	mutex     sync.RWMutex
+	sessions  map[string]Session
+	lastPrune time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}, lastPrune: time.Now()}
+}
+
+func storeKey(phoneNumberID, waID string) string {
+	return phoneNumberID + ":" + waID
+}
+
+func (ms *MemoryStore) Get(ctx context.Context, phoneNumberID, waID string) (*Session, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	session, found := ms.sessions[storeKey(phoneNumberID, waID)]
+	if !found {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (ms *MemoryStore) Set(ctx context.Context, session Session) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.sessions[storeKey(session.PhoneNumberID, session.WaID)] = session
+
+	// remove the sessions with the closed windows
+	now := time.Now()
+	if now.Sub(ms.lastPrune) > CustomerServiceWindow {
+		for key, stored := range ms.sessions {
+			if stored.WindowRemaining(now) == 0 && stored.EntryPointRemaining(now) == 0 {
+				delete(ms.sessions, key)
+			}
+		}
+		ms.lastPrune = now
+	}
+	return nil
+}
+
+// Tracker tracks the customer service window of the users, fed by the inbound webhook messages
+type Tracker struct {
+	logger *zap.Logger
+	store  Store
+	mutex  sync.Mutex
+}
+
+// NewTracker returns a session tracker, keeps the sessions in memory if the store is nil
+func NewTracker(ctx context.Context, store Store) *Tracker {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Tracker{
+		logger: logger.Named("session_tracker"),
+		store:  store,
+	}
+}
+
+// Record updates the session from the inbound message, received by the phone number.
+// system messages (example: user changed number) are not user initiated and ignored
+func (t *Tracker) Record(ctx context.Context, phoneNumberID string, message *whatsappTY.InboundMessage) error {
+	if message == nil || message.From == "" || message.Type == whatsappTY.MESSAGE_TYPE_SYSTEM {
+		return nil
+	}
+	receivedAt := message.Time()
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	waID := customClient.NormalizePhoneNumber(message.From)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	session, err := t.store.Get(ctx, phoneNumberID, waID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		session = &Session{PhoneNumberID: phoneNumberID, WaID: waID}
+	}
+
+	// webhooks can be delivered out of order, keep the latest
+	updated := false
+	if receivedAt.After(session.LastMessageAt) {
+		session.LastMessageAt = receivedAt
+		updated = true
+	}
+	if message.Referral != nil && receivedAt.After(session.EntryPointAt) {
+		session.EntryPointAt = receivedAt
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+	return t.store.Set(ctx, *session)
+}
+
+// Get returns the session of the user, nil if no message received from the user
+func (t *Tracker) Get(ctx context.Context, phoneNumberID, to string) (*Session, error) {
+	return t.store.Get(ctx, phoneNumberID, customClient.NormalizePhoneNumber(to))
+}
+
+// Remaining returns the remaining time of the customer service window, zero if closed
+func (t *Tracker) Remaining(ctx context.Context, phoneNumberID, to string) (time.Duration, error) {
+	session, err := t.Get(ctx, phoneNumberID, to)
+	if err != nil || session == nil {
+		return 0, err
+	}
+	return session.WindowRemaining(time.Now()), nil
+}
+
+// EntryPointRemaining returns the remaining time of the free entry point window, zero if closed
+func (t *Tracker) EntryPointRemaining(ctx context.Context, phoneNumberID, to string) (time.Duration, error) {
+	session, err := t.Get(ctx, phoneNumberID, to)
+	if err != nil || session == nil {
+		return 0, err
+	}
+	return session.EntryPointRemaining(time.Now()), nil
+}
+
+// CanSendFreeForm reports the customer service window is open, non template messages can be sent
+func (t *Tracker) CanSendFreeForm(ctx context.Context, phoneNumberID, to string) (bool, error) {
+	left, err := t.Remaining(ctx, phoneNumberID, to)
+	return left > 0, err
+}
+
+// Middleware records the inbound messages of the webhook events
+func (t *Tracker) Middleware() webhook.Middleware {
+	return func(next webhook.HandlerFunc) webhook.HandlerFunc {
+		return func(ctx context.Context, event *webhook.Event) error {
+			if event.Kind == webhook.EventKindMessage {
+				if err := t.Record(ctx, event.PhoneNumberID, event.Message); err != nil {
+					logger := loggerUtils.FromContextOrDefault(ctx, t.logger)
+					logger.Error("error on recording the session", zap.String("phoneNumberId", event.PhoneNumberID), zap.Error(err))
+				}
+			}
+			return next(ctx, event)
+		}
+	}
+}
@@ -1,16 +1,93 @@
 package types
 
+import "time"
+
 type Config struct {
-	WhatsApp WhatsAppConfig `yaml:"whatsapp"`
-	Logger   LoggerConfig   `yaml:"logger"`
+	WhatsApp WhatsAppConfig  `yaml:"whatsapp"`
+	Accounts []AccountConfig `yaml:"accounts"` // multiple business accounts, whatsapp config used as defaults
+	Logger   LoggerConfig    `yaml:"logger"`
 }
 
 // whatsapp client configuration
 type WhatsAppConfig struct {
-	Version           string `yaml:"version"`
-	BusinessAccountID string `yaml:"business_account_id"`
-	PhoneNumberID     string `yaml:"phone_number_id"`
-	AccessToken       string `yaml:"access_token"`
+	Version           string           `yaml:"version"`
+	AppID             string           `yaml:"app_id"` // used in resumable upload api
+	BusinessAccountID string           `yaml:"business_account_id"`
+	PhoneNumberID     string           `yaml:"phone_number_id"`
+	AccessToken       string           `yaml:"access_token"`
+	SkipValidation    bool             `yaml:"skip_validation"` // skips the message and media validation before posting
+	Retry             RetryConfig      `yaml:"retry"`
+	RateLimit         RateLimitConfig  `yaml:"rate_limit"`
+	Webhook           WebhookConfig    `yaml:"webhook"`
+	MediaCache        MediaCacheConfig `yaml:"media_cache"`
+	Queue             QueueConfig      `yaml:"queue"`
+}
+
+// business account with the phone numbers
+// empty fields are taken from the whatsapp config
+type AccountConfig struct {
+	Name              string              `yaml:"name"`
+	AppID             string              `yaml:"app_id"`
+	BusinessAccountID string              `yaml:"business_account_id"`
+	AccessToken       string              `yaml:"access_token"`
+	PhoneNumbers      []PhoneNumberConfig `yaml:"phone_numbers"`
+}
+
+// phone number of a business account
+type PhoneNumberConfig struct {
+	Alias         string   `yaml:"alias"` // default: phone_number_id
+	PhoneNumberID string   `yaml:"phone_number_id"`
+	CountryCodes  []string `yaml:"country_codes"` // recipient calling codes served by the number, example: "91", "1"
+	Default       bool     `yaml:"default"`       // used when no country code matches, default: first phone number
+}
+
+// retry configuration for the transient failures
+type RetryConfig struct {
+	MaxAttempts   int           `yaml:"max_attempts"` // includes the first attempt, 0 or 1 disables retry
+	BaseDelay     time.Duration `yaml:"base_delay"`
+	MaxDelay      time.Duration `yaml:"max_delay"`       // caps the backoff and the "Retry-After" delay, default: 30s
+	Jitter        *float64      `yaml:"jitter"`          // 0.0 to 1.0, default: 0.2, 0 disables jitter
+	RetryOnStatus []int         `yaml:"retry_on_status"` // retried on all the methods, default: 429
+	RetryOnCodes  []int         `yaml:"retry_on_codes"`  // graph api error codes, retried on all the methods
+	// retries POST requests on 5xx and network errors, can send duplicate messages
+	RetryNonIdempotent bool `yaml:"retry_non_idempotent"`
+}
+
+// client side rate limit configuration
+type RateLimitConfig struct {
+	Enabled           bool          `yaml:"enabled"`
+	MessagesPerSecond float64       `yaml:"messages_per_second"` // per phone number id, default: 80
+	Burst             int           `yaml:"burst"`               // default: messages_per_second
+	PairInterval      time.Duration `yaml:"pair_interval"`       // per recipient, default: 6s, negative disables
+	Mode              string        `yaml:"mode"`                // options: block, non_blocking. default: block
+}
+
+// webhook receiver configuration
+type WebhookConfig struct {
+	VerifyToken             string        `yaml:"verify_token"`
+	AppSecret               string        `yaml:"app_secret"`
+	SkipSignatureValidation bool          `yaml:"skip_signature_validation"` // do not use in production
+	MaxBodySize             int64         `yaml:"max_body_size"`             // in bytes, default: 3 MiB
+	ReplayWindow            time.Duration `yaml:"replay_window"`             // default: 10m, negative disables
+}
+
+// uploaded media id cache configuration
+type MediaCacheConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Path       string        `yaml:"path"`        // json file, keeps the cache in memory if empty
+	TTL        time.Duration `yaml:"ttl"`         // default: 29 days, uploaded media is available for 30 days
+	MaxEntries int           `yaml:"max_entries"` // least recently used entries are evicted, default: 10000
+}
+
+// outbound message queue configuration
+type QueueConfig struct {
+	Workers      int           `yaml:"workers"`       // default: 4
+	MaxAttempts  int           `yaml:"max_attempts"`  // moved to dead letters after, default: 5
+	PollInterval time.Duration `yaml:"poll_interval"` // default: 1s
+	BaseDelay    time.Duration `yaml:"base_delay"`    // retry backoff, default: 5s
+	MaxDelay     time.Duration `yaml:"max_delay"`     // default: 10m
+	Path         string        `yaml:"path"`          // json file, keeps the queue in memory if empty
+	Retention    time.Duration `yaml:"retention"`     // sent and dead jobs are purged after, default: 168h, negative disables
 }
 
 // logger configuration
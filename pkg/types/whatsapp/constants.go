@@ -6,10 +6,117 @@ const (
 
 	DEFAULT_MESSAGING_PRODUCT = "whatsapp"
 
+	RECIPIENT_TYPE_INDIVIDUAL = "individual"
+
 	// message types
 	MESSAGE_TYPE_TEXT        = "text"
 	MESSAGE_TYPE_TEMPLATE    = "template"
 	MESSAGE_TYPE_INTERACTIVE = "interactive"
+	MESSAGE_TYPE_AUDIO       = "audio"
+	MESSAGE_TYPE_BUTTON      = "button"
+	MESSAGE_TYPE_CONTACTS    = "contacts"
+	MESSAGE_TYPE_DOCUMENT    = "document"
+	MESSAGE_TYPE_IMAGE       = "image"
+	MESSAGE_TYPE_LOCATION    = "location"
+	MESSAGE_TYPE_ORDER       = "order"
+	MESSAGE_TYPE_REACTION    = "reaction"
+	MESSAGE_TYPE_STICKER     = "sticker"
+	MESSAGE_TYPE_SYSTEM      = "system"
+	MESSAGE_TYPE_UNSUPPORTED = "unsupported"
+	MESSAGE_TYPE_VIDEO       = "video"
+
+	// inbound interactive types
+	INTERACTIVE_TYPE_BUTTON_REPLY = "button_reply"
+	INTERACTIVE_TYPE_LIST_REPLY   = "list_reply"
+	INTERACTIVE_TYPE_NFM_REPLY    = "nfm_reply"
+
+	// message statuses
+	MESSAGE_STATUS_SENT      = "sent"
+	MESSAGE_STATUS_DELIVERED = "delivered"
+	MESSAGE_STATUS_READ      = "read"
+	MESSAGE_STATUS_FAILED    = "failed"
+
+	TYPING_INDICATOR_TEXT = "text"
+
+	// message statuses of the template send response
+	MESSAGE_STATUS_ACCEPTED                    = "accepted"
+	MESSAGE_STATUS_HELD_FOR_QUALITY_ASSESSMENT = "held_for_quality_assessment"
+
+	// webhook
+	WEBHOOK_OBJECT_WHATSAPP_BUSINESS_ACCOUNT = "whatsapp_business_account"
+
+	WEBHOOK_FIELD_MESSAGES                       = "messages"
+	WEBHOOK_FIELD_MESSAGE_TEMPLATE_STATUS_UPDATE = "message_template_status_update"
+
+	// template categories
+	TEMPLATE_CATEGORY_AUTHENTICATION = "AUTHENTICATION"
+	TEMPLATE_CATEGORY_MARKETING      = "MARKETING"
+	TEMPLATE_CATEGORY_UTILITY        = "UTILITY"
+
+	// template statuses
+	TEMPLATE_STATUS_APPROVED = "APPROVED"
+	TEMPLATE_STATUS_PENDING  = "PENDING"
+	TEMPLATE_STATUS_REJECTED = "REJECTED"
+	TEMPLATE_STATUS_PAUSED   = "PAUSED"
+	TEMPLATE_STATUS_DISABLED = "DISABLED"
+
+	// template component types
+	TEMPLATE_COMPONENT_HEADER  = "HEADER"
+	TEMPLATE_COMPONENT_BODY    = "BODY"
+	TEMPLATE_COMPONENT_FOOTER  = "FOOTER"
+	TEMPLATE_COMPONENT_BUTTONS = "BUTTONS"
+
+	// template header formats
+	TEMPLATE_FORMAT_TEXT     = "TEXT"
+	TEMPLATE_FORMAT_IMAGE    = "IMAGE"
+	TEMPLATE_FORMAT_VIDEO    = "VIDEO"
+	TEMPLATE_FORMAT_DOCUMENT = "DOCUMENT"
+	TEMPLATE_FORMAT_LOCATION = "LOCATION"
+
+	// template button types
+	TEMPLATE_BUTTON_QUICK_REPLY  = "QUICK_REPLY"
+	TEMPLATE_BUTTON_URL          = "URL"
+	TEMPLATE_BUTTON_PHONE_NUMBER = "PHONE_NUMBER"
+	TEMPLATE_BUTTON_OTP          = "OTP"
+	TEMPLATE_BUTTON_COPY_CODE    = "COPY_CODE"
+	TEMPLATE_BUTTON_FLOW         = "FLOW"
+	TEMPLATE_BUTTON_CATALOG      = "CATALOG"
+	TEMPLATE_BUTTON_MPM          = "MPM"
+
+	// business profile verticals
+	VERTICAL_UNDEFINED     = "UNDEFINED"
+	VERTICAL_OTHER         = "OTHER"
+	VERTICAL_AUTO          = "AUTO"
+	VERTICAL_BEAUTY        = "BEAUTY"
+	VERTICAL_APPAREL       = "APPAREL"
+	VERTICAL_EDU           = "EDU"
+	VERTICAL_ENTERTAIN     = "ENTERTAIN"
+	VERTICAL_EVENT_PLAN    = "EVENT_PLAN"
+	VERTICAL_FINANCE       = "FINANCE"
+	VERTICAL_GROCERY       = "GROCERY"
+	VERTICAL_GOVT          = "GOVT"
+	VERTICAL_HOTEL         = "HOTEL"
+	VERTICAL_HEALTH        = "HEALTH"
+	VERTICAL_NONPROFIT     = "NONPROFIT"
+	VERTICAL_PROF_SERVICES = "PROF_SERVICES"
+	VERTICAL_RETAIL        = "RETAIL"
+	VERTICAL_TRAVEL        = "TRAVEL"
+	VERTICAL_RESTAURANT    = "RESTAURANT"
+	VERTICAL_NOT_A_BIZ     = "NOT_A_BIZ"
+
+	// phone number verification code methods
+	CODE_METHOD_SMS   = "SMS"
+	CODE_METHOD_VOICE = "VOICE"
+
+	// phone number quality ratings
+	QUALITY_RATING_GREEN  = "GREEN"
+	QUALITY_RATING_YELLOW = "YELLOW"
+	QUALITY_RATING_RED    = "RED"
+	QUALITY_RATING_NA     = "NA"
+
+	// phone number throughput levels
+	THROUGHPUT_LEVEL_STANDARD = "STANDARD"
+	THROUGHPUT_LEVEL_HIGH     = "HIGH"
 
 	// Languages
 	LANG_ENGLISH    = "en"
@@ -0,0 +1,58 @@
+package whatsapp
+
+// business phone number
+// https://developers.facebook.com/docs/graph-api/reference/whats-app-business-account-to-number-current-status
+type PhoneNumber struct {
+	ID                     string                 `json:"id,omitempty"`
+	DisplayPhoneNumber     string                 `json:"display_phone_number,omitempty"`
+	VerifiedName           string                 `json:"verified_name,omitempty"`
+	QualityRating          string                 `json:"quality_rating,omitempty"`       // options: GREEN, YELLOW, RED, NA
+	MessagingLimitTier     string                 `json:"messaging_limit_tier,omitempty"` // options: TIER_50, TIER_250, TIER_1K, TIER_10K, TIER_100K, TIER_UNLIMITED
+	NameStatus             string                 `json:"name_status,omitempty"`          // options: APPROVED, AVAILABLE_WITHOUT_REVIEW, DECLINED, EXPIRED, PENDING_REVIEW, NONE
+	NewNameStatus          string                 `json:"new_name_status,omitempty"`
+	CodeVerificationStatus string                 `json:"code_verification_status,omitempty"` // options: VERIFIED, NOT_VERIFIED, EXPIRED
+	Throughput             *PhoneNumberThroughput `json:"throughput,omitempty"`
+	PlatformType           string                 `json:"platform_type,omitempty"` // options: CLOUD_API, ON_PREMISE, NOT_APPLICABLE
+	Status                 string                 `json:"status,omitempty"`        // options: CONNECTED, PENDING, DISCONNECTED, FLAGGED, ...
+	AccountMode            string                 `json:"account_mode,omitempty"`  // options: SANDBOX, LIVE
+	IsPinEnabled           bool                   `json:"is_pin_enabled,omitempty"`
+	IsOfficialBusiness     bool                   `json:"is_official_business_account,omitempty"`
+	LastOnboardedTime      string                 `json:"last_onboarded_time,omitempty"`
+}
+
+type PhoneNumberThroughput struct {
+	Level string `json:"level,omitempty"` // options: STANDARD, HIGH, NOT_APPLICABLE
+}
+
+type PhoneNumberList struct {
+	Data   []PhoneNumber `json:"data,omitempty"`
+	Paging *Paging       `json:"paging,omitempty"`
+}
+
+type PhoneNumberListOptions struct {
+	Fields string `json:"fields,omitempty"` // comma separated
+	Limit  int    `json:"limit,omitempty"`
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+}
+
+// register and two-step verification request
+type PhoneNumberPINRequest struct {
+	MessagingProduct       string `json:"messaging_product,omitempty"`
+	PIN                    string `json:"pin,omitempty"`
+	DataLocalizationRegion string `json:"data_localization_region,omitempty"` // register only, ISO 3166 alpha-2 country code
+}
+
+// optional parameters of the phone number registration
+type PhoneNumberRegisterOptions struct {
+	// stores the message data at rest in the given region, ISO 3166 alpha-2 country code, example: DE
+	// https://developers.facebook.com/docs/whatsapp/cloud-api/overview/local-storage
+	DataLocalizationRegion string
+}
+
+// verification code request
+type PhoneNumberCodeRequest struct {
+	CodeMethod string `json:"code_method,omitempty"` // options: SMS, VOICE
+	Language   string `json:"language,omitempty"`    // example: en_US
+	Code       string `json:"code,omitempty"`        // verify only
+}
@@ -0,0 +1,144 @@
+package whatsapp
+
+import (
+	"encoding/json"
+)
+
+// message template definition
+// https://developers.facebook.com/docs/whatsapp/business-management-api/message-templates
+type Template struct {
+	ID                    string                `json:"id,omitempty"`
+	Name                  string                `json:"name,omitempty"`
+	Language              string                `json:"language,omitempty"`
+	Category              string                `json:"category,omitempty"` // options: AUTHENTICATION, MARKETING, UTILITY
+	Status                string                `json:"status,omitempty"`   // options: APPROVED, PENDING, REJECTED, PAUSED, DISABLED, ...
+	RejectedReason        string                `json:"rejected_reason,omitempty"`
+	QualityScore          *TemplateQualityScore `json:"quality_score,omitempty"`
+	ParameterFormat       string                `json:"parameter_format,omitempty"` // options: POSITIONAL, NAMED
+	AllowCategoryChange   bool                  `json:"allow_category_change,omitempty"`
+	MessageSendTTLSeconds int                   `json:"message_send_ttl_seconds,omitempty"`
+	Components            []TemplateComponent   `json:"components,omitempty"`
+}
+
+type TemplateQualityScore struct {
+	Score string `json:"score,omitempty"` // options: GREEN, YELLOW, RED, UNKNOWN
+	Date  int64  `json:"date,omitempty"`
+}
+
+type TemplateComponent struct {
+	Type    string           `json:"type,omitempty"`   // options: HEADER, BODY, FOOTER, BUTTONS
+	Format  string           `json:"format,omitempty"` // header only, options: TEXT, IMAGE, VIDEO, DOCUMENT, LOCATION
+	Text    string           `json:"text,omitempty"`
+	Example *TemplateExample `json:"example,omitempty"`
+	Buttons []TemplateButton `json:"buttons,omitempty"`
+
+	// authentication templates
+	AddSecurityRecommendation bool `json:"add_security_recommendation,omitempty"` // body
+	CodeExpirationMinutes     int  `json:"code_expiration_minutes,omitempty"`     // footer
+}
+
+type TemplateExample struct {
+	HeaderText            []string             `json:"header_text,omitempty"`
+	HeaderHandle          []string             `json:"header_handle,omitempty"` // uploaded file handle, resumable upload api
+	BodyText              [][]string           `json:"body_text,omitempty"`
+	HeaderTextNamedParams []TemplateNamedParam `json:"header_text_named_params,omitempty"`
+	BodyTextNamedParams   []TemplateNamedParam `json:"body_text_named_params,omitempty"`
+}
+
+type TemplateNamedParam struct {
+	ParamName string `json:"param_name,omitempty"`
+	Example   string `json:"example,omitempty"`
+}
+
+type TemplateButton struct {
+	Type        string     `json:"type,omitempty"` // options: QUICK_REPLY, URL, PHONE_NUMBER, OTP, COPY_CODE, FLOW, CATALOG, MPM
+	Text        string     `json:"text,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	PhoneNumber string     `json:"phone_number,omitempty"`
+	Example     StringList `json:"example,omitempty"` // url variable example or copy code example
+
+	// otp button
+	OTPType       string                 `json:"otp_type,omitempty"` // options: COPY_CODE, ONE_TAP, ZERO_TAP
+	AutofillText  string                 `json:"autofill_text,omitempty"`
+	SupportedApps []TemplateSupportedApp `json:"supported_apps,omitempty"`
+
+	// flow button
+	FlowID         string `json:"flow_id,omitempty"`
+	FlowName       string `json:"flow_name,omitempty"`
+	FlowJSON       string `json:"flow_json,omitempty"`
+	FlowAction     string `json:"flow_action,omitempty"` // options: navigate, data_exchange
+	NavigateScreen string `json:"navigate_screen,omitempty"`
+}
+
+type TemplateSupportedApp struct {
+	PackageName   string `json:"package_name,omitempty"`
+	SignatureHash string `json:"signature_hash,omitempty"`
+}
+
+// copy code button example is a string, url button example is a list
+func (tb TemplateButton) MarshalJSON() ([]byte, error) {
+	type alias TemplateButton
+	if tb.Type == TEMPLATE_BUTTON_COPY_CODE && len(tb.Example) == 1 {
+		return json.Marshal(struct {
+			alias
+			Example string `json:"example"`
+		}{alias: alias(tb), Example: tb.Example[0]})
+	}
+	return json.Marshal(alias(tb))
+}
+
+// StringList accepts a string or a list of strings
+type StringList []string
+
+func (sl *StringList) UnmarshalJSON(data []byte) error {
+	single := ""
+	if err := json.Unmarshal(data, &single); err == nil {
+		*sl = StringList{single}
+		return nil
+	}
+	list := []string{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*sl = list
+	return nil
+}
+
+type TemplateListOptions struct {
+	Fields   string `json:"fields,omitempty"` // comma separated
+	Limit    int    `json:"limit,omitempty"`
+	After    string `json:"after,omitempty"`
+	Before   string `json:"before,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Category string `json:"category,omitempty"`
+	Language string `json:"language,omitempty"`
+	Name     string `json:"name,omitempty"` // partial match
+}
+
+type TemplateList struct {
+	Data   []Template `json:"data,omitempty"`
+	Paging *Paging    `json:"paging,omitempty"`
+}
+
+type TemplateCreateResponse struct {
+	ID       string `json:"id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Category string `json:"category,omitempty"`
+}
+
+// graph api cursor based pagination
+type Paging struct {
+	Cursors  *PagingCursors `json:"cursors,omitempty"`
+	Next     string         `json:"next,omitempty"`
+	Previous string         `json:"previous,omitempty"`
+}
+
+type PagingCursors struct {
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// HasNext reports more results available
+func (p *Paging) HasNext() bool {
+	return p != nil && p.Next != "" && p.Cursors != nil && p.Cursors.After != ""
+}
@@ -1,18 +1,35 @@
 package whatsapp
 
+import (
+	"encoding/json"
+	"io"
+)
+
 type StatusResponse struct {
 	Success bool `json:"success,omitempty"`
 }
 
 type BusinessProfile struct {
-	About             string   `json:"about,omitempty"`
-	Address           string   `json:"address,omitempty"`
-	Description       string   `json:"description,omitempty"`
-	Email             string   `json:"email,omitempty"`
-	MessagingProduct  string   `json:"messaging_product,omitempty"`
-	ProfilePictureURL string   `json:"profile_picture_url,omitempty"`
-	Vertical          string   `json:"vertical,omitempty"`
-	Websites          []string `json:"websites,omitempty"`
+	About                string   `json:"about,omitempty"`
+	Address              string   `json:"address,omitempty"`
+	Description          string   `json:"description,omitempty"`
+	Email                string   `json:"email,omitempty"`
+	MessagingProduct     string   `json:"messaging_product,omitempty"`
+	ProfilePictureURL    string   `json:"profile_picture_url,omitempty"`
+	ProfilePictureHandle string   `json:"profile_picture_handle,omitempty"` // used in update, handle from resumable upload api
+	Vertical             string   `json:"vertical,omitempty"`
+	Websites             []string `json:"websites,omitempty"`
+}
+
+// resumable upload session
+type UploadSession struct {
+	ID         string `json:"id,omitempty"`
+	FileOffset int64  `json:"file_offset"`
+}
+
+// resumable upload result
+type UploadResult struct {
+	Handle string `json:"h,omitempty"`
 }
 
 type Media struct {
@@ -23,6 +40,18 @@ type Media struct {
 	Caption          string `json:"caption,omitempty"`
 	MediaType        string `json:"type,omitempty"`
 	MessagingProduct string `json:"messaging_product,omitempty"`
+
+	// available in retrieve
+	URL      string `json:"url,omitempty"` // valid for 5 minutes, requires the access token to download
+	MimeType string `json:"mime_type,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	FileSize int64  `json:"file_size,omitempty"`
+
+	// streaming sources, used in upload
+	Open     func() (io.ReadCloser, error) `json:"-"` // reopens the source on each attempt, enables retry
+	Reader   io.Reader                     `json:"-"` // retried only if it is an io.ReadSeeker
+	Size     int64                         `json:"-"` // size of the Open and Reader sources, 0 if unknown
+	Progress func(uploaded, total int64)   `json:"-"` // upload progress, total is 0 if unknown
 }
 
 // https://developers.facebook.com/docs/whatsapp/cloud-api/reference/messages
@@ -32,14 +61,16 @@ type Message struct {
 
 	// object types
 	Audio       *MessageMediaObject    `json:"audio,omitempty"`
-	Contacts    interface{}            `json:"contacts,omitempty"`
+	Contacts    []ContactObject        `json:"contacts,omitempty"`
 	Document    *MessageMediaObject    `json:"document,omitempty"`
 	Image       *MessageMediaObject    `json:"image,omitempty"`
 	Interactive *InteractiveObject     `json:"interactive,omitempty"`
-	Location    interface{}            `json:"location,omitempty"`
+	Location    *LocationObject        `json:"location,omitempty"`
+	Reaction    *MessageReactionObject `json:"reaction,omitempty"`
 	Sticker     *MessageMediaObject    `json:"sticker,omitempty"`
 	Template    *MessageTemplateObject `json:"template,omitempty"`
 	Text        *MessageTextObject     `json:"text,omitempty"`
+	Video       *MessageMediaObject    `json:"video,omitempty"`
 
 	MessagingProduct string `json:"messaging_product,omitempty"`
 	PreviewURL       bool   `json:"preview_url,omitempty"`
@@ -49,11 +80,117 @@ type Message struct {
 	Type             string `json:"type,omitempty"` // optional, default: text
 }
 
+// MediaObject returns the media object of the message type, nil if the message has no media
+func (m *Message) MediaObject() *MessageMediaObject {
+	switch m.Type {
+	case MESSAGE_TYPE_AUDIO:
+		return m.Audio
+	case MESSAGE_TYPE_DOCUMENT:
+		return m.Document
+	case MESSAGE_TYPE_IMAGE:
+		return m.Image
+	case MESSAGE_TYPE_STICKER:
+		return m.Sticker
+	case MESSAGE_TYPE_VIDEO:
+		return m.Video
+	}
+	return nil
+}
+
+// SetMediaObject sets the media object of the message type
+func (m *Message) SetMediaObject(object *MessageMediaObject) {
+	switch m.Type {
+	case MESSAGE_TYPE_AUDIO:
+		m.Audio = object
+	case MESSAGE_TYPE_DOCUMENT:
+		m.Document = object
+	case MESSAGE_TYPE_IMAGE:
+		m.Image = object
+	case MESSAGE_TYPE_STICKER:
+		m.Sticker = object
+	case MESSAGE_TYPE_VIDEO:
+		m.Video = object
+	}
+}
+
 type MessageResponse struct {
-	MessagingProduct string        `json:"messaging_product,omitempty"`
-	Contacts         interface{}   `json:"contacts,omitempty"`
-	WaID             string        `json:"wa_id,omitempty"`
-	Messages         []interface{} `json:"messages,omitempty"`
+	MessagingProduct string                   `json:"messaging_product,omitempty"`
+	Contacts         []MessageResponseContact `json:"contacts,omitempty"`
+	Messages         []MessageResponseMessage `json:"messages,omitempty"`
+	// Deprecated: filled from the first contact for the compatibility, use RecipientWaID
+	WaID string `json:"wa_id,omitempty"`
+}
+
+type MessageResponseContact struct {
+	Input string `json:"input,omitempty"` // phone number used in the request
+	WaID  string `json:"wa_id,omitempty"`
+}
+
+type MessageResponseMessage struct {
+	ID            string `json:"id,omitempty"`             // wamid
+	MessageStatus string `json:"message_status,omitempty"` // template sends only, options: accepted, held_for_quality_assessment
+}
+
+// MessageID returns the id (wamid) of the sent message
+func (mr *MessageResponse) MessageID() string {
+	if len(mr.Messages) == 0 {
+		return ""
+	}
+	return mr.Messages[0].ID
+}
+
+// RecipientWaID returns the whatsapp id of the recipient
+func (mr *MessageResponse) RecipientWaID() string {
+	if len(mr.Contacts) == 0 {
+		return mr.WaID
+	}
+	return mr.Contacts[0].WaID
+}
+
+func (mr *MessageResponse) UnmarshalJSON(data []byte) error {
+	type alias MessageResponse
+	err := json.Unmarshal(data, (*alias)(mr))
+	if err != nil {
+		return err
+	}
+	if mr.WaID == "" {
+		mr.WaID = mr.RecipientWaID()
+	}
+	return nil
+}
+
+// MessageStatus returns the status of the sent message, available only for template sends
+func (mr *MessageResponse) MessageStatus() string {
+	if len(mr.Messages) == 0 {
+		return ""
+	}
+	return mr.Messages[0].MessageStatus
+}
+
+// IsPaced reports the marketing template message is held by template pacing,
+// the message will be delivered or dropped based on the quality assessment
+// https://developers.facebook.com/docs/whatsapp/message-templates/guidelines#template-pacing
+func (mr *MessageResponse) IsPaced() bool {
+	return mr.MessageStatus() == MESSAGE_STATUS_HELD_FOR_QUALITY_ASSESSMENT
+}
+
+// used to mark the received message as read and to show the typing indicator
+// https://developers.facebook.com/docs/whatsapp/cloud-api/guides/mark-message-as-read
+type MessageStatusRequest struct {
+	MessagingProduct string           `json:"messaging_product,omitempty"`
+	Status           string           `json:"status,omitempty"` // value: read
+	MessageID        string           `json:"message_id,omitempty"`
+	TypingIndicator  *TypingIndicator `json:"typing_indicator,omitempty"`
+}
+
+type TypingIndicator struct {
+	Type string `json:"type,omitempty"` // value: text
+}
+
+// result of marking a message as read
+type ReadReceipt struct {
+	MessageID string `json:"message_id,omitempty"`
+	Success   bool   `json:"success,omitempty"`
 }
 
 type MessageContext struct {
@@ -63,7 +200,7 @@ type MessageContext struct {
 // object definitions
 
 // media object
-// used in: audio, document, image, sticker
+// used in: audio, document, image, sticker, video
 type MessageMediaObject struct {
 	ID       string `json:"id,omitempty"`
 	Link     string `json:"link,omitempty"`
@@ -93,13 +230,25 @@ type ComponentObject struct {
 }
 
 type ParameterObject struct {
-	Type     string          `json:"type,omitempty"` // options: currency, date_time, document, image, text, video
-	Text     string          `json:"text,omitempty"`
-	Currency *CurrencyObject `json:"currency,omitempty"`
-	DateTime *DateTimeObject `json:"date_time,omitempty"`
-	Image    *Media          `json:"image,omitempty"`
-	Document *Media          `json:"document,omitempty"`
-	Video    *Media          `json:"video,omitempty"`
+	Type          string                 `json:"type,omitempty"`           // options: currency, date_time, document, image, text, video, location, payload, coupon_code, action
+	ParameterName string                 `json:"parameter_name,omitempty"` // named parameter templates
+	Text          string                 `json:"text,omitempty"`
+	Currency      *CurrencyObject        `json:"currency,omitempty"`
+	DateTime      *DateTimeObject        `json:"date_time,omitempty"`
+	Image         *Media                 `json:"image,omitempty"`
+	Document      *Media                 `json:"document,omitempty"`
+	Video         *Media                 `json:"video,omitempty"`
+	Location      *LocationObject        `json:"location,omitempty"`
+	Payload       string                 `json:"payload,omitempty"`     // quick reply button
+	CouponCode    string                 `json:"coupon_code,omitempty"` // copy code button
+	Action        *ParameterActionObject `json:"action,omitempty"`      // flow, catalog and mpm buttons
+}
+
+type ParameterActionObject struct {
+	FlowToken                  string          `json:"flow_token,omitempty"`                    // flow button
+	FlowActionData             map[string]any  `json:"flow_action_data,omitempty"`              // flow button
+	ThumbnailProductRetailerID string          `json:"thumbnail_product_retailer_id,omitempty"` // catalog and mpm buttons
+	Sections                   []SectionObject `json:"sections,omitempty"`                      // mpm button
 }
 
 type CurrencyObject struct {
@@ -146,14 +295,37 @@ type ActionObject struct {
 	Button             string              `json:"button,omitempty"`
 	Buttons            []InteractiveButton `json:"buttons,omitempty"`
 	CatalogID          string              `json:"catalog_id,omitempty"`
-	ProductRetailerID  string              `json:"product_retainer_id,omitempty"`
-	Sections           []any               `json:"sections,omitempty"`
+	ProductRetailerID  string              `json:"product_retailer_id,omitempty"`
+	Sections           []SectionObject     `json:"sections,omitempty"`
 	Mode               string              `json:"mode,omitempty"`
 	FlowMessageVersion string              `json:"flow_message_version,omitempty"` // must be 3
 	FlowToken          string              `json:"flow_token,omitempty"`
 	FlowID             string              `json:"flow_id,omitempty"`
 	FlowAction         string              `json:"flow_action,omitempty"`
-	FlowActionPayload  any                 `json:"flow_action_payload,omitempty"`
+	FlowActionPayload  *FlowActionPayload  `json:"flow_action_payload,omitempty"`
+}
+
+// section object
+// used in: list, product_list
+type SectionObject struct {
+	Title        string              `json:"title,omitempty"`
+	Rows         []SectionRowObject  `json:"rows,omitempty"`          // list
+	ProductItems []SectionProductRow `json:"product_items,omitempty"` // product_list
+}
+
+type SectionRowObject struct {
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type SectionProductRow struct {
+	ProductRetailerID string `json:"product_retailer_id,omitempty"`
+}
+
+type FlowActionPayload struct {
+	Screen string         `json:"screen,omitempty"`
+	Data   map[string]any `json:"data,omitempty"`
 }
 
 type InteractiveButton struct {
@@ -0,0 +1,80 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessageMediaObject(t *testing.T) {
+	object := &MessageMediaObject{Link: "https://example.com/file"}
+
+	tests := []struct {
+		name    string
+		message Message
+		want    bool
+	}{
+		{name: "audio", message: Message{Type: MESSAGE_TYPE_AUDIO, Audio: object}, want: true},
+		{name: "document", message: Message{Type: MESSAGE_TYPE_DOCUMENT, Document: object}, want: true},
+		{name: "image", message: Message{Type: MESSAGE_TYPE_IMAGE, Image: object}, want: true},
+		{name: "sticker", message: Message{Type: MESSAGE_TYPE_STICKER, Sticker: object}, want: true},
+		{name: "video", message: Message{Type: MESSAGE_TYPE_VIDEO, Video: object}, want: true},
+		{name: "type mismatch", message: Message{Type: MESSAGE_TYPE_IMAGE, Video: object}, want: false},
+		{name: "text", message: Message{Type: MESSAGE_TYPE_TEXT, Text: &MessageTextObject{Body: "hi"}}, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.message.MediaObject(); (got == object) != tc.want {
+				t.Fatalf("media object = %v, want found %v", got, tc.want)
+			}
+			if !tc.want {
+				return
+			}
+			replaced := &MessageMediaObject{ID: "media-1"}
+			tc.message.SetMediaObject(replaced)
+			if got := tc.message.MediaObject(); got != replaced {
+				t.Errorf("media object after set = %v, want %v", got, replaced)
+			}
+		})
+	}
+}
+
+func TestMessageResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantRecipient string
+		wantMessageID string
+	}{
+		{
+			name:          "contacts",
+			data:          `{"messaging_product":"whatsapp","contacts":[{"input":"+919876543210","wa_id":"919876543210"}],"messages":[{"id":"wamid.1"}]}`,
+			wantRecipient: "919876543210",
+			wantMessageID: "wamid.1",
+		},
+		{
+			name:          "legacy wa id",
+			data:          `{"messaging_product":"whatsapp","wa_id":"919876543210","messages":[{"id":"wamid.1"}]}`,
+			wantRecipient: "919876543210",
+			wantMessageID: "wamid.1",
+		},
+		{name: "empty", data: `{}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			response := &MessageResponse{}
+			if err := json.Unmarshal([]byte(tc.data), response); err != nil {
+				t.Fatal(err)
+			}
+			if got := response.RecipientWaID(); got != tc.wantRecipient {
+				t.Errorf("RecipientWaID = %q, want %q", got, tc.wantRecipient)
+			}
+			// deprecated field kept in sync with the contacts
+			if response.WaID != tc.wantRecipient {
+				t.Errorf("WaID = %q, want %q", response.WaID, tc.wantRecipient)
+			}
+			if got := response.MessageID(); got != tc.wantMessageID {
+				t.Errorf("MessageID = %q, want %q", got, tc.wantMessageID)
+			}
+		})
+	}
+}
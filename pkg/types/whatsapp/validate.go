@@ -0,0 +1,506 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// documented limits of the cloud api
+// https://developers.facebook.com/docs/whatsapp/cloud-api/reference/messages
+const (
+	MaxTextBodyLength            = 4096
+	MaxCaptionLength             = 1024
+	MaxInteractiveBodyLength     = 1024
+	MaxInteractiveHeaderLength   = 60
+	MaxInteractiveFooterLength   = 60
+	MaxReplyButtons              = 3
+	MaxReplyButtonTitleLength    = 20
+	MaxReplyButtonIDLength       = 256
+	MaxListButtonLength          = 20
+	MaxListSections              = 10
+	MaxListRows                  = 10
+	MaxListSectionTitleLength    = 24
+	MaxListRowTitleLength        = 24
+	MaxListRowIDLength           = 200
+	MaxListRowDescriptionLength  = 72
+	MaxProductListProducts       = 30
+	MaxTemplateNameLength        = 512
+	MaxBizOpaqueCallbackDataSize = 512
+
+	// business profile
+	MaxProfileAboutLength       = 139
+	MaxProfileAddressLength     = 256
+	MaxProfileDescriptionLength = 512
+	MaxProfileEmailLength       = 128
+	MaxProfileWebsites          = 2
+	MaxProfileWebsiteLength     = 256
+
+	// media upload, in bytes
+	// https://developers.facebook.com/docs/whatsapp/cloud-api/reference/media#supported-media-types
+	MaxAudioSize           = 16 << 20
+	MaxDocumentSize        = 100 << 20
+	MaxImageSize           = 5 << 20
+	MaxStickerSize         = 100 << 10
+	MaxAnimatedStickerSize = 500 << 10
+	MaxVideoSize           = 16 << 20
+)
+
+var businessVerticals = map[string]bool{
+	VERTICAL_UNDEFINED: true, VERTICAL_OTHER: true, VERTICAL_AUTO: true, VERTICAL_BEAUTY: true,
+	VERTICAL_APPAREL: true, VERTICAL_EDU: true, VERTICAL_ENTERTAIN: true, VERTICAL_EVENT_PLAN: true,
+	VERTICAL_FINANCE: true, VERTICAL_GROCERY: true, VERTICAL_GOVT: true, VERTICAL_HOTEL: true,
+	VERTICAL_HEALTH: true, VERTICAL_NONPROFIT: true, VERTICAL_PROF_SERVICES: true, VERTICAL_RETAIL: true,
+	VERTICAL_TRAVEL: true, VERTICAL_RESTAURANT: true, VERTICAL_NOT_A_BIZ: true,
+}
+
+var phoneNumberRegex = regexp.MustCompile(`^\+?[1-9][0-9]{6,14}$`)
+
+// ValidationError reports a constraint violation of a field
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (ve *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ve.Field, ve.Reason)
+}
+
+// collects the validation errors
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) maxLength(field, value string, max int) {
+	if length := utf8.RuneCountInString(value); length > max {
+		v.add(field, "length %d exceeds the limit %d", length, max)
+	}
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "required")
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+// Validate verifies the message against the cloud api limits,
+// returns all the violations joined as a single error
+func (m *Message) Validate() error {
+	v := &validator{}
+
+	// "to" format, spaces, dashes and brackets are allowed
+	to := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(m.To)
+	if to == "" {
+		v.add("to", "required")
+	} else if !phoneNumberRegex.MatchString(to) {
+		v.add("to", "invalid phone number %q, expected E.164 format", m.To)
+	}
+
+	if m.MessagingProduct != "" && m.MessagingProduct != DEFAULT_MESSAGING_PRODUCT {
+		v.add("messaging_product", "must be %q", DEFAULT_MESSAGING_PRODUCT)
+	}
+	v.maxLength("biz_opaque_callback_data", m.BizOpaqueCallbackData, MaxBizOpaqueCallbackDataSize)
+	if m.Context != nil {
+		v.required("context.message_id", m.Context.MessageID)
+	}
+
+	messageType := m.Type
+	if messageType == "" {
+		messageType = MESSAGE_TYPE_TEXT
+	}
+
+	// exactly one content object, matching the type
+	populated := m.populatedObjects()
+	if len(populated) == 0 {
+		v.add("type", "no content object found for the type %q", messageType)
+	} else if len(populated) > 1 {
+		v.add("type", "exactly one content object expected, found %v", populated)
+	} else if populated[0] != messageType {
+		v.add("type", "type %q does not match the content object %q", messageType, populated[0])
+	}
+
+	switch messageType {
+	case MESSAGE_TYPE_TEXT:
+		if m.Text != nil {
+			v.required("text.body", m.Text.Body)
+			v.maxLength("text.body", m.Text.Body, MaxTextBodyLength)
+		}
+
+	case MESSAGE_TYPE_IMAGE, MESSAGE_TYPE_VIDEO, MESSAGE_TYPE_DOCUMENT, MESSAGE_TYPE_AUDIO, MESSAGE_TYPE_STICKER:
+		validateMediaObject(v, messageType, m.mediaObject(messageType))
+
+	case MESSAGE_TYPE_LOCATION:
+		if m.Location != nil {
+			if math.Abs(m.Location.Latitude) > 90 {
+				v.add("location.latitude", "must be between -90 and 90")
+			}
+			if math.Abs(m.Location.Longitude) > 180 {
+				v.add("location.longitude", "must be between -180 and 180")
+			}
+		}
+
+	case MESSAGE_TYPE_CONTACTS:
+		for index, contact := range m.Contacts {
+			if contact.Name == nil || contact.Name.FormattedName == "" {
+				v.add(fmt.Sprintf("contacts[%d].name.formatted_name", index), "required")
+			}
+		}
+
+	case MESSAGE_TYPE_REACTION:
+		if m.Reaction != nil {
+			// empty emoji removes the reaction
+			v.required("reaction.message_id", m.Reaction.MessageID)
+		}
+
+	case MESSAGE_TYPE_TEMPLATE:
+		if m.Template != nil {
+			v.errs = append(v.errs, unwrapJoined(m.Template.Validate())...)
+		}
+
+	case MESSAGE_TYPE_INTERACTIVE:
+		if m.Interactive != nil {
+			v.errs = append(v.errs, unwrapJoined(m.Interactive.Validate())...)
+		}
+	}
+
+	return v.err()
+}
+
+// returns the types of the populated content objects
+func (m *Message) populatedObjects() []string {
+	objects := []string{}
+	if m.Audio != nil {
+		objects = append(objects, MESSAGE_TYPE_AUDIO)
+	}
+	if len(m.Contacts) > 0 {
+		objects = append(objects, MESSAGE_TYPE_CONTACTS)
+	}
+	if m.Document != nil {
+		objects = append(objects, MESSAGE_TYPE_DOCUMENT)
+	}
+	if m.Image != nil {
+		objects = append(objects, MESSAGE_TYPE_IMAGE)
+	}
+	if m.Interactive != nil {
+		objects = append(objects, MESSAGE_TYPE_INTERACTIVE)
+	}
+	if m.Location != nil {
+		objects = append(objects, MESSAGE_TYPE_LOCATION)
+	}
+	if m.Reaction != nil {
+		objects = append(objects, MESSAGE_TYPE_REACTION)
+	}
+	if m.Sticker != nil {
+		objects = append(objects, MESSAGE_TYPE_STICKER)
+	}
+	if m.Template != nil {
+		objects = append(objects, MESSAGE_TYPE_TEMPLATE)
+	}
+	if m.Text != nil {
+		objects = append(objects, MESSAGE_TYPE_TEXT)
+	}
+	if m.Video != nil {
+		objects = append(objects, MESSAGE_TYPE_VIDEO)
+	}
+	return objects
+}
+
+func (m *Message) mediaObject(messageType string) *MessageMediaObject {
+	switch messageType {
+	case MESSAGE_TYPE_IMAGE:
+		return m.Image
+	case MESSAGE_TYPE_VIDEO:
+		return m.Video
+	case MESSAGE_TYPE_DOCUMENT:
+		return m.Document
+	case MESSAGE_TYPE_AUDIO:
+		return m.Audio
+	case MESSAGE_TYPE_STICKER:
+		return m.Sticker
+	}
+	return nil
+}
+
+func validateMediaObject(v *validator, messageType string, media *MessageMediaObject) {
+	if media == nil {
+		return
+	}
+	if media.ID == "" && media.Link == "" {
+		v.add(messageType, "either id or link required")
+	} else if media.ID != "" && media.Link != "" {
+		v.add(messageType, "either id or link allowed, not both")
+	}
+
+	// caption allowed only on image, video and document
+	if media.Caption != "" {
+		switch messageType {
+		case MESSAGE_TYPE_IMAGE, MESSAGE_TYPE_VIDEO, MESSAGE_TYPE_DOCUMENT:
+			v.maxLength(messageType+".caption", media.Caption, MaxCaptionLength)
+		default:
+			v.add(messageType+".caption", "caption not supported on %s", messageType)
+		}
+	}
+
+	if media.Filename != "" && messageType != MESSAGE_TYPE_DOCUMENT {
+		v.add(messageType+".filename", "filename supported only on document")
+	}
+}
+
+// Validate verifies the template object
+func (t *MessageTemplateObject) Validate() error {
+	v := &validator{}
+	v.required("template.name", t.Name)
+	v.maxLength("template.name", t.Name, MaxTemplateNameLength)
+	v.required("template.language.code", t.Language.Code)
+
+	// component and parameter types are not restricted, the api adds new types over the time
+	for index, component := range t.Components {
+		field := fmt.Sprintf("template.components[%d]", index)
+		switch strings.ToLower(component.Type) {
+		case "button":
+			v.required(field+".sub_type", component.SubType)
+			v.required(field+".index", component.Index)
+		case "":
+			v.add(field+".type", "required")
+		}
+
+		for paramIndex, param := range component.Parameters {
+			paramField := fmt.Sprintf("%s.parameters[%d]", field, paramIndex)
+			switch param.Type {
+			case "text":
+				v.required(paramField+".text", param.Text)
+			case "currency":
+				if param.Currency == nil {
+					v.add(paramField+".currency", "required")
+				}
+			case "date_time":
+				if param.DateTime == nil {
+					v.add(paramField+".date_time", "required")
+				}
+			case "image":
+				if param.Image == nil {
+					v.add(paramField+".image", "required")
+				}
+			case "document":
+				if param.Document == nil {
+					v.add(paramField+".document", "required")
+				}
+			case "video":
+				if param.Video == nil {
+					v.add(paramField+".video", "required")
+				}
+			case "":
+				v.add(paramField+".type", "required")
+			}
+		}
+	}
+
+	return v.err()
+}
+
+// Validate verifies the interactive object
+func (i *InteractiveObject) Validate() error {
+	v := &validator{}
+
+	// body is optional only for product
+	if i.Body == nil {
+		if i.Type != "product" {
+			v.add("interactive.body", "required")
+		}
+	} else {
+		v.required("interactive.body.text", i.Body.Text)
+		v.maxLength("interactive.body.text", i.Body.Text, MaxInteractiveBodyLength)
+	}
+
+	if i.Footer != nil {
+		v.maxLength("interactive.footer.text", i.Footer.Text, MaxInteractiveFooterLength)
+	}
+
+	if i.Header != nil {
+		if i.Header.Type == "text" || i.Header.Text != "" {
+			v.maxLength("interactive.header.text", i.Header.Text, MaxInteractiveHeaderLength)
+		}
+		if i.Type == "list" && i.Header.Type != "text" {
+			v.add("interactive.header.type", "only text header supported on list")
+		}
+	}
+
+	if i.Action == nil {
+		v.add("interactive.action", "required")
+		return v.err()
+	}
+
+	// constraints of the known types, other types are not restricted, the api adds new types over the time
+	switch i.Type {
+	case "button":
+		validateReplyButtons(v, i.Action.Buttons)
+
+	case "list":
+		v.required("interactive.action.button", i.Action.Button)
+		v.maxLength("interactive.action.button", i.Action.Button, MaxListButtonLength)
+		validateListSections(v, i.Action.Sections)
+
+	case "product":
+		v.required("interactive.action.catalog_id", i.Action.CatalogID)
+		v.required("interactive.action.product_retailer_id", i.Action.ProductRetailerID)
+
+	case "product_list":
+		if i.Header == nil {
+			v.add("interactive.header", "required")
+		}
+		v.required("interactive.action.catalog_id", i.Action.CatalogID)
+		validateProductSections(v, i.Action.Sections)
+
+	case "flow":
+		v.required("interactive.action.flow_id", i.Action.FlowID)
+
+	case "":
+		v.add("interactive.type", "required")
+	}
+
+	return v.err()
+}
+
+func validateReplyButtons(v *validator, buttons []InteractiveButton) {
+	if len(buttons) == 0 {
+		v.add("interactive.action.buttons", "at least one button required")
+	} else if len(buttons) > MaxReplyButtons {
+		v.add("interactive.action.buttons", "%d buttons exceeds the limit %d", len(buttons), MaxReplyButtons)
+	}
+
+	ids := map[string]bool{}
+	titles := map[string]bool{}
+	for index, button := range buttons {
+		field := fmt.Sprintf("interactive.action.buttons[%d]", index)
+		id, title := button.ID, button.Title
+		if button.Reply != nil {
+			id, title = button.Reply.ID, button.Reply.Title
+		}
+		v.required(field+".id", id)
+		v.maxLength(field+".id", id, MaxReplyButtonIDLength)
+		v.required(field+".title", title)
+		v.maxLength(field+".title", title, MaxReplyButtonTitleLength)
+		if id != "" && ids[id] {
+			v.add(field+".id", "duplicate id %q", id)
+		}
+		if title != "" && titles[title] {
+			v.add(field+".title", "duplicate title %q", title)
+		}
+		ids[id] = true
+		titles[title] = true
+	}
+}
+
+func validateListSections(v *validator, sections []SectionObject) {
+	if len(sections) == 0 {
+		v.add("interactive.action.sections", "at least one section required")
+	} else if len(sections) > MaxListSections {
+		v.add("interactive.action.sections", "%d sections exceeds the limit %d", len(sections), MaxListSections)
+	}
+
+	totalRows := 0
+	ids := map[string]bool{}
+	for index, section := range sections {
+		field := fmt.Sprintf("interactive.action.sections[%d]", index)
+		// title required, if more than one section
+		if len(sections) > 1 {
+			v.required(field+".title", section.Title)
+		}
+		v.maxLength(field+".title", section.Title, MaxListSectionTitleLength)
+		if len(section.Rows) == 0 {
+			v.add(field+".rows", "at least one row required")
+		}
+		totalRows += len(section.Rows)
+
+		for rowIndex, row := range section.Rows {
+			rowField := fmt.Sprintf("%s.rows[%d]", field, rowIndex)
+			v.required(rowField+".id", row.ID)
+			v.maxLength(rowField+".id", row.ID, MaxListRowIDLength)
+			v.required(rowField+".title", row.Title)
+			v.maxLength(rowField+".title", row.Title, MaxListRowTitleLength)
+			v.maxLength(rowField+".description", row.Description, MaxListRowDescriptionLength)
+			if row.ID != "" && ids[row.ID] {
+				v.add(rowField+".id", "duplicate id %q", row.ID)
+			}
+			ids[row.ID] = true
+		}
+	}
+
+	if totalRows > MaxListRows {
+		v.add("interactive.action.sections", "%d rows exceeds the limit %d", totalRows, MaxListRows)
+	}
+}
+
+func validateProductSections(v *validator, sections []SectionObject) {
+	if len(sections) == 0 {
+		v.add("interactive.action.sections", "at least one section required")
+	} else if len(sections) > MaxListSections {
+		v.add("interactive.action.sections", "%d sections exceeds the limit %d", len(sections), MaxListSections)
+	}
+
+	totalProducts := 0
+	for index, section := range sections {
+		field := fmt.Sprintf("interactive.action.sections[%d]", index)
+		if len(sections) > 1 {
+			v.required(field+".title", section.Title)
+		}
+		v.maxLength(field+".title", section.Title, MaxListSectionTitleLength)
+		if len(section.ProductItems) == 0 {
+			v.add(field+".product_items", "at least one product required")
+		}
+		totalProducts += len(section.ProductItems)
+	}
+
+	if totalProducts > MaxProductListProducts {
+		v.add("interactive.action.sections", "%d products exceeds the limit %d", totalProducts, MaxProductListProducts)
+	}
+}
+
+// Validate verifies the business profile update against the api limits
+// https://developers.facebook.com/docs/whatsapp/cloud-api/reference/business-profiles
+func (bp *BusinessProfile) Validate() error {
+	v := &validator{}
+	v.maxLength("about", bp.About, MaxProfileAboutLength)
+	v.maxLength("address", bp.Address, MaxProfileAddressLength)
+	v.maxLength("description", bp.Description, MaxProfileDescriptionLength)
+	v.maxLength("email", bp.Email, MaxProfileEmailLength)
+	if bp.Email != "" && !strings.Contains(bp.Email, "@") {
+		v.add("email", "invalid email %q", bp.Email)
+	}
+	if len(bp.Websites) > MaxProfileWebsites {
+		v.add("websites", "%d websites exceeds the limit %d", len(bp.Websites), MaxProfileWebsites)
+	}
+	for index, website := range bp.Websites {
+		field := fmt.Sprintf("websites[%d]", index)
+		v.maxLength(field, website, MaxProfileWebsiteLength)
+		if !strings.HasPrefix(website, "http://") && !strings.HasPrefix(website, "https://") {
+			v.add(field, "must start with http:// or https://")
+		}
+	}
+	if bp.Vertical != "" && !businessVerticals[bp.Vertical] {
+		v.add("vertical", "unsupported vertical %q", bp.Vertical)
+	}
+	return v.err()
+}
+
+// returns the errors of a joined error
+func unwrapJoined(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
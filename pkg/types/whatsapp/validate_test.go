@@ -0,0 +1,181 @@
+package whatsapp
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// returns the sorted fields of the validation errors
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	fields := []string{}
+	for _, e := range unwrapJoined(err) {
+		var validationErr *ValidationError
+		if !errors.As(e, &validationErr) {
+			t.Fatalf("unexpected error type %T: %v", e, e)
+		}
+		fields = append(fields, validationErr.Field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestMessageValidate(t *testing.T) {
+	textMessage := func(to, body string) Message {
+		return Message{To: to, Type: MESSAGE_TYPE_TEXT, Text: &MessageTextObject{Body: body}}
+	}
+	buttons := func(count int) []InteractiveButton {
+		buttons := []InteractiveButton{}
+		for index := 0; index < count; index++ {
+			id := string(rune('a' + index))
+			buttons = append(buttons, InteractiveButton{Type: "reply", Reply: &InteractiveReplyButton{ID: id, Title: "title " + id}})
+		}
+		return buttons
+	}
+
+	tests := []struct {
+		name       string
+		message    Message
+		wantFields []string
+	}{
+		{name: "valid text", message: textMessage("+91 98765 43210", "hello")},
+		{name: "default type is text", message: Message{To: "919876543210", Text: &MessageTextObject{Body: "hello"}}},
+		{name: "missing recipient", message: textMessage("", "hello"), wantFields: []string{"to"}},
+		{name: "invalid recipient", message: textMessage("12ab", "hello"), wantFields: []string{"to"}},
+		{name: "empty text body", message: textMessage("919876543210", " "), wantFields: []string{"text.body"}},
+		{name: "text body too long", message: textMessage("919876543210", strings.Repeat("a", MaxTextBodyLength+1)), wantFields: []string{"text.body"}},
+		{
+			name:       "invalid messaging product",
+			message:    Message{To: "919876543210", MessagingProduct: "sms", Text: &MessageTextObject{Body: "hello"}},
+			wantFields: []string{"messaging_product"},
+		},
+		{
+			name:       "missing content object",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_IMAGE},
+			wantFields: []string{"type"},
+		},
+		{
+			name:       "type mismatch",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_IMAGE, Text: &MessageTextObject{Body: "hello"}},
+			wantFields: []string{"type"},
+		},
+		{
+			name:       "media without id and link",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_IMAGE, Image: &MessageMediaObject{}},
+			wantFields: []string{"image"},
+		},
+		{
+			name:       "caption on audio",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_AUDIO, Audio: &MessageMediaObject{ID: "1", Caption: "caption"}},
+			wantFields: []string{"audio.caption"},
+		},
+		{
+			name:       "filename on image",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_IMAGE, Image: &MessageMediaObject{ID: "1", Filename: "a.png"}},
+			wantFields: []string{"image.filename"},
+		},
+		{
+			name:       "invalid location",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_LOCATION, Location: &LocationObject{Latitude: 91, Longitude: -181}},
+			wantFields: []string{"location.latitude", "location.longitude"},
+		},
+		{
+			name: "valid template",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_TEMPLATE, Template: &MessageTemplateObject{
+				Name: "order_update", Language: Language{Code: "en_US"},
+				Components: []ComponentObject{
+					{Type: "body", Parameters: []ParameterObject{{Type: "text", Text: "123"}}},
+					{Type: "button", SubType: "quick_reply", Index: "0", Parameters: []ParameterObject{{Type: "payload", Payload: "yes"}}},
+				},
+			}},
+		},
+		{
+			name: "unknown template component type allowed",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_TEMPLATE, Template: &MessageTemplateObject{
+				Name: "carousel", Language: Language{Code: "en_US"},
+				Components: []ComponentObject{{Type: "carousel"}, {Type: "limited_time_offer"}},
+			}},
+		},
+		{
+			name: "invalid template",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_TEMPLATE, Template: &MessageTemplateObject{
+				Components: []ComponentObject{
+					{Type: "", Parameters: []ParameterObject{{Type: "text"}}},
+					{Type: "button"},
+				},
+			}},
+			wantFields: []string{
+				"template.components[0].parameters[0].text", "template.components[0].type",
+				"template.components[1].index", "template.components[1].sub_type",
+				"template.language.code", "template.name",
+			},
+		},
+		{
+			name: "valid reply buttons",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_INTERACTIVE, Interactive: &InteractiveObject{
+				Type: "button", Body: &TextObject{Text: "choose"}, Action: &ActionObject{Buttons: buttons(3)},
+			}},
+		},
+		{
+			name: "too many reply buttons",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_INTERACTIVE, Interactive: &InteractiveObject{
+				Type: "button", Body: &TextObject{Text: "choose"}, Action: &ActionObject{Buttons: buttons(4)},
+			}},
+			wantFields: []string{"interactive.action.buttons"},
+		},
+		{
+			name: "duplicate reply button",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_INTERACTIVE, Interactive: &InteractiveObject{
+				Type: "button", Body: &TextObject{Text: "choose"}, Action: &ActionObject{Buttons: append(buttons(1), buttons(1)...)},
+			}},
+			wantFields: []string{"interactive.action.buttons[1].id", "interactive.action.buttons[1].title"},
+		},
+		{
+			name: "list rows above the limit",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_INTERACTIVE, Interactive: &InteractiveObject{
+				Type: "list", Body: &TextObject{Text: "choose"}, Header: &HeaderObject{Type: "image"},
+				Action: &ActionObject{Button: "options", Sections: []SectionObject{{Rows: []SectionRowObject{
+					{ID: "1", Title: "1"}, {ID: "2", Title: "2"}, {ID: "3", Title: "3"}, {ID: "4", Title: "4"},
+					{ID: "5", Title: "5"}, {ID: "6", Title: "6"}, {ID: "7", Title: "7"}, {ID: "8", Title: "8"},
+					{ID: "9", Title: "9"}, {ID: "10", Title: "10"}, {ID: "11", Title: "11"},
+				}}}},
+			}},
+			wantFields: []string{"interactive.action.sections", "interactive.header.type"},
+		},
+		{
+			name: "unknown interactive type allowed",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_INTERACTIVE, Interactive: &InteractiveObject{
+				Type: "cta_url", Body: &TextObject{Text: "visit"}, Action: &ActionObject{},
+			}},
+		},
+		{
+			name: "interactive without action and type",
+			message: Message{To: "919876543210", Type: MESSAGE_TYPE_INTERACTIVE, Interactive: &InteractiveObject{
+				Body: &TextObject{Text: "hello"},
+			}},
+			wantFields: []string{"interactive.action"},
+		},
+		{
+			name:       "reaction without message id",
+			message:    Message{To: "919876543210", Type: MESSAGE_TYPE_REACTION, Reaction: &MessageReactionObject{Emoji: "👍"}},
+			wantFields: []string{"reaction.message_id"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.message.Validate()
+			got := validationFields(t, err)
+			want := tc.wantFields
+			if want == nil {
+				want = []string{}
+			}
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("fields = %v, want %v (error: %v)", got, want, err)
+			}
+		})
+	}
+}
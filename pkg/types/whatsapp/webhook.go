@@ -0,0 +1,461 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhook notification payload
+// https://developers.facebook.com/docs/whatsapp/cloud-api/webhooks/components
+//
+// unknown fields of the notification, entry, change, value, message and status objects
+// are preserved as raw json in the "Extra" field
+type Notification struct {
+	Object string                     `json:"object,omitempty"` // value: whatsapp_business_account
+	Entry  []NotificationEntry        `json:"entry,omitempty"`
+	Extra  map[string]json.RawMessage `json:"-"`
+}
+
+type NotificationEntry struct {
+	ID      string                     `json:"id,omitempty"` // whatsapp business account id
+	Time    int64                      `json:"time,omitempty"`
+	Changes []NotificationChange       `json:"changes,omitempty"`
+	Extra   map[string]json.RawMessage `json:"-"`
+}
+
+type NotificationChange struct {
+	Field string                     `json:"field,omitempty"` // options: messages, message_template_status_update, ...
+	Value *NotificationValue         `json:"value,omitempty"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type NotificationValue struct {
+	MessagingProduct string                `json:"messaging_product,omitempty"`
+	Metadata         *NotificationMetadata `json:"metadata,omitempty"`
+	Contacts         []NotificationContact `json:"contacts,omitempty"`
+	Messages         []InboundMessage      `json:"messages,omitempty"`
+	Statuses         []MessageStatus       `json:"statuses,omitempty"`
+	Errors           []NotificationError   `json:"errors,omitempty"`
+
+	// used in "message_template_status_update" field
+	Event                   string `json:"event,omitempty"` // options: APPROVED, REJECTED, PENDING_DELETION, ...
+	MessageTemplateID       int64  `json:"message_template_id,omitempty"`
+	MessageTemplateName     string `json:"message_template_name,omitempty"`
+	MessageTemplateLanguage string `json:"message_template_language,omitempty"`
+	Reason                  string `json:"reason,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type NotificationMetadata struct {
+	DisplayPhoneNumber string `json:"display_phone_number,omitempty"`
+	PhoneNumberID      string `json:"phone_number_id,omitempty"`
+}
+
+type NotificationContact struct {
+	WaID    string                      `json:"wa_id,omitempty"`
+	Profile *NotificationContactProfile `json:"profile,omitempty"`
+}
+
+type NotificationContactProfile struct {
+	Name string `json:"name,omitempty"`
+}
+
+type NotificationError struct {
+	Code      int                    `json:"code,omitempty"`
+	Title     string                 `json:"title,omitempty"`
+	Message   string                 `json:"message,omitempty"`
+	ErrorData *NotificationErrorData `json:"error_data,omitempty"`
+	Href      string                 `json:"href,omitempty"`
+}
+
+type NotificationErrorData struct {
+	Details string `json:"details,omitempty"`
+}
+
+// inbound message, received from the user
+type InboundMessage struct {
+	From      string                 `json:"from,omitempty"`
+	ID        string                 `json:"id,omitempty"`
+	Timestamp string                 `json:"timestamp,omitempty"` // unix time in seconds
+	Type      string                 `json:"type,omitempty"`
+	Context   *InboundMessageContext `json:"context,omitempty"`
+	Identity  *InboundIdentity       `json:"identity,omitempty"`
+	Referral  *InboundReferral       `json:"referral,omitempty"`
+	Errors    []NotificationError    `json:"errors,omitempty"` // used in unsupported type
+
+	// object types
+	Audio       *InboundMediaObject       `json:"audio,omitempty"`
+	Button      *InboundButtonObject      `json:"button,omitempty"`
+	Contacts    []ContactObject           `json:"contacts,omitempty"`
+	Document    *InboundMediaObject       `json:"document,omitempty"`
+	Image       *InboundMediaObject       `json:"image,omitempty"`
+	Interactive *InboundInteractiveObject `json:"interactive,omitempty"`
+	Location    *LocationObject           `json:"location,omitempty"`
+	Order       *InboundOrderObject       `json:"order,omitempty"`
+	Reaction    *MessageReactionObject    `json:"reaction,omitempty"`
+	Sticker     *InboundMediaObject       `json:"sticker,omitempty"`
+	System      *InboundSystemObject      `json:"system,omitempty"`
+	Text        *InboundTextObject        `json:"text,omitempty"`
+	Video       *InboundMediaObject       `json:"video,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type InboundMessageContext struct {
+	From                string                  `json:"from,omitempty"`
+	ID                  string                  `json:"id,omitempty"`
+	Forwarded           bool                    `json:"forwarded,omitempty"`
+	FrequentlyForwarded bool                    `json:"frequently_forwarded,omitempty"`
+	ReferredProduct     *InboundReferredProduct `json:"referred_product,omitempty"`
+}
+
+type InboundReferredProduct struct {
+	CatalogID         string `json:"catalog_id,omitempty"`
+	ProductRetailerID string `json:"product_retailer_id,omitempty"`
+}
+
+type InboundIdentity struct {
+	Acknowledged     bool   `json:"acknowledged,omitempty"`
+	CreatedTimestamp int64  `json:"created_timestamp,omitempty"`
+	Hash             string `json:"hash,omitempty"`
+}
+
+// click to whatsapp ads referral
+type InboundReferral struct {
+	SourceURL    string `json:"source_url,omitempty"`
+	SourceType   string `json:"source_type,omitempty"` // options: ad, post
+	SourceID     string `json:"source_id,omitempty"`
+	Headline     string `json:"headline,omitempty"`
+	Body         string `json:"body,omitempty"`
+	MediaType    string `json:"media_type,omitempty"`
+	ImageURL     string `json:"image_url,omitempty"`
+	VideoURL     string `json:"video_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	CtwaClid     string `json:"ctwa_clid,omitempty"`
+}
+
+type InboundTextObject struct {
+	Body string `json:"body,omitempty"`
+}
+
+// used in: audio, document, image, sticker, video
+type InboundMediaObject struct {
+	ID       string `json:"id,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Animated bool   `json:"animated,omitempty"` // sticker
+	Voice    bool   `json:"voice,omitempty"`    // audio
+}
+
+// quick reply button of a template message
+type InboundButtonObject struct {
+	Payload string `json:"payload,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+type InboundInteractiveObject struct {
+	Type        string              `json:"type,omitempty"` // options: button_reply, list_reply, nfm_reply
+	ButtonReply *InboundButtonReply `json:"button_reply,omitempty"`
+	ListReply   *InboundListReply   `json:"list_reply,omitempty"`
+	NfmReply    *InboundFlowReply   `json:"nfm_reply,omitempty"`
+}
+
+type InboundButtonReply struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+type InboundListReply struct {
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// flow completion reply
+type InboundFlowReply struct {
+	Name         string `json:"name,omitempty"` // value: flow
+	Body         string `json:"body,omitempty"`
+	ResponseJSON string `json:"response_json,omitempty"`
+}
+
+type InboundOrderObject struct {
+	CatalogID    string               `json:"catalog_id,omitempty"`
+	Text         string               `json:"text,omitempty"`
+	ProductItems []InboundProductItem `json:"product_items,omitempty"`
+}
+
+type InboundProductItem struct {
+	ProductRetailerID string  `json:"product_retailer_id,omitempty"`
+	Quantity          int     `json:"quantity,omitempty"`
+	ItemPrice         float64 `json:"item_price,omitempty"`
+	Currency          string  `json:"currency,omitempty"`
+}
+
+type InboundSystemObject struct {
+	Body     string `json:"body,omitempty"`
+	Identity string `json:"identity,omitempty"`
+	NewWaID  string `json:"new_wa_id,omitempty"`
+	WaID     string `json:"wa_id,omitempty"`
+	Type     string `json:"type,omitempty"` // options: customer_changed_number, customer_identity_changed
+	Customer string `json:"customer,omitempty"`
+}
+
+// status of the sent message
+type MessageStatus struct {
+	ID                    string              `json:"id,omitempty"`
+	Status                string              `json:"status,omitempty"` // options: sent, delivered, read, failed
+	Timestamp             string              `json:"timestamp,omitempty"`
+	RecipientID           string              `json:"recipient_id,omitempty"`
+	BizOpaqueCallbackData string              `json:"biz_opaque_callback_data,omitempty"`
+	Conversation          *StatusConversation `json:"conversation,omitempty"`
+	Pricing               *StatusPricing      `json:"pricing,omitempty"`
+	Errors                []NotificationError `json:"errors,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type StatusConversation struct {
+	ID                  string                    `json:"id,omitempty"`
+	ExpirationTimestamp string                    `json:"expiration_timestamp,omitempty"`
+	Origin              *StatusConversationOrigin `json:"origin,omitempty"`
+}
+
+type StatusConversationOrigin struct {
+	Type string `json:"type,omitempty"` // options: authentication, marketing, utility, service, referral_conversion
+}
+
+type StatusPricing struct {
+	Billable     bool   `json:"billable,omitempty"`
+	PricingModel string `json:"pricing_model,omitempty"`
+	Category     string `json:"category,omitempty"`
+}
+
+// location object
+// used in: inbound and outbound messages
+type LocationObject struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+	URL       string  `json:"url,omitempty"`
+}
+
+// contact object
+// used in: inbound and outbound messages
+type ContactObject struct {
+	Addresses []ContactAddress `json:"addresses,omitempty"`
+	Birthday  string           `json:"birthday,omitempty"` // format: YYYY-MM-DD
+	Emails    []ContactEmail   `json:"emails,omitempty"`
+	Name      *ContactName     `json:"name,omitempty"`
+	Org       *ContactOrg      `json:"org,omitempty"`
+	Phones    []ContactPhone   `json:"phones,omitempty"`
+	URLs      []ContactURL     `json:"urls,omitempty"`
+}
+
+type ContactAddress struct {
+	Street      string `json:"street,omitempty"`
+	City        string `json:"city,omitempty"`
+	State       string `json:"state,omitempty"`
+	Zip         string `json:"zip,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+	Type        string `json:"type,omitempty"` // options: HOME, WORK
+}
+
+type ContactEmail struct {
+	Email string `json:"email,omitempty"`
+	Type  string `json:"type,omitempty"` // options: HOME, WORK
+}
+
+type ContactName struct {
+	FormattedName string `json:"formatted_name,omitempty"`
+	FirstName     string `json:"first_name,omitempty"`
+	LastName      string `json:"last_name,omitempty"`
+	MiddleName    string `json:"middle_name,omitempty"`
+	Suffix        string `json:"suffix,omitempty"`
+	Prefix        string `json:"prefix,omitempty"`
+}
+
+type ContactOrg struct {
+	Company    string `json:"company,omitempty"`
+	Department string `json:"department,omitempty"`
+	Title      string `json:"title,omitempty"`
+}
+
+type ContactPhone struct {
+	Phone string `json:"phone,omitempty"`
+	WaID  string `json:"wa_id,omitempty"`
+	Type  string `json:"type,omitempty"` // options: CELL, MAIN, IPHONE, HOME, WORK
+}
+
+type ContactURL struct {
+	URL  string `json:"url,omitempty"`
+	Type string `json:"type,omitempty"` // options: HOME, WORK
+}
+
+// Time returns the timestamp of the message
+func (m *InboundMessage) Time() time.Time {
+	return parseUnixTimestamp(m.Timestamp)
+}
+
+// Time returns the timestamp of the status
+func (s *MessageStatus) Time() time.Time {
+	return parseUnixTimestamp(s.Timestamp)
+}
+
+func parseUnixTimestamp(timestamp string) time.Time {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	type alias Notification
+	extra, err := unmarshalWithExtra(data, (*alias)(n))
+	n.Extra = extra
+	return err
+}
+
+func (n Notification) MarshalJSON() ([]byte, error) {
+	type alias Notification
+	return marshalWithExtra((alias)(n), n.Extra)
+}
+
+func (ne *NotificationEntry) UnmarshalJSON(data []byte) error {
+	type alias NotificationEntry
+	extra, err := unmarshalWithExtra(data, (*alias)(ne))
+	ne.Extra = extra
+	return err
+}
+
+func (ne NotificationEntry) MarshalJSON() ([]byte, error) {
+	type alias NotificationEntry
+	return marshalWithExtra((alias)(ne), ne.Extra)
+}
+
+func (nc *NotificationChange) UnmarshalJSON(data []byte) error {
+	type alias NotificationChange
+	extra, err := unmarshalWithExtra(data, (*alias)(nc))
+	nc.Extra = extra
+	return err
+}
+
+func (nc NotificationChange) MarshalJSON() ([]byte, error) {
+	type alias NotificationChange
+	return marshalWithExtra((alias)(nc), nc.Extra)
+}
+
+func (nv *NotificationValue) UnmarshalJSON(data []byte) error {
+	type alias NotificationValue
+	extra, err := unmarshalWithExtra(data, (*alias)(nv))
+	nv.Extra = extra
+	return err
+}
+
+func (nv NotificationValue) MarshalJSON() ([]byte, error) {
+	type alias NotificationValue
+	return marshalWithExtra((alias)(nv), nv.Extra)
+}
+
+func (m *InboundMessage) UnmarshalJSON(data []byte) error {
+	type alias InboundMessage
+	extra, err := unmarshalWithExtra(data, (*alias)(m))
+	m.Extra = extra
+	return err
+}
+
+func (m InboundMessage) MarshalJSON() ([]byte, error) {
+	type alias InboundMessage
+	return marshalWithExtra((alias)(m), m.Extra)
+}
+
+func (s *MessageStatus) UnmarshalJSON(data []byte) error {
+	type alias MessageStatus
+	extra, err := unmarshalWithExtra(data, (*alias)(s))
+	s.Extra = extra
+	return err
+}
+
+func (s MessageStatus) MarshalJSON() ([]byte, error) {
+	type alias MessageStatus
+	return marshalWithExtra((alias)(s), s.Extra)
+}
+
+// json field names of a struct type
+var knownFieldsCache sync.Map // reflect.Type => map[string]bool
+
+func knownFields(t reflect.Type) map[string]bool {
+	if fields, found := knownFieldsCache.Load(t); found {
+		return fields.(map[string]bool)
+	}
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = true
+	}
+	knownFieldsCache.Store(t, fields)
+	return fields
+}
+
+// unmarshals the data into the target struct pointer,
+// returns the fields not defined in the target struct
+func unmarshalWithExtra(data []byte, target any) (map[string]json.RawMessage, error) {
+	err := json.Unmarshal(data, target)
+	if err != nil {
+		return nil, err
+	}
+
+	all := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := knownFields(reflect.TypeOf(target).Elem())
+	for name := range all {
+		if fields[name] {
+			delete(all, name)
+		}
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// marshals the source struct and includes the extra fields
+func marshalWithExtra(source any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(source)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	all := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, found := all[name]; !found {
+			all[name] = value
+		}
+	}
+	return json.Marshal(all)
+}
@@ -28,6 +28,19 @@ func FromContext(ctx context.Context) (*zap.Logger, error) {
 	return logger, nil
 }
 
+// FromContextOrDefault returns the logger from the context,
+// if not available returns the given default logger
+func FromContextOrDefault(ctx context.Context, defaultLogger *zap.Logger) *zap.Logger {
+	if ctx == nil {
+		return defaultLogger
+	}
+	logger, err := FromContext(ctx)
+	if err != nil {
+		return defaultLogger
+	}
+	return logger
+}
+
 func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
 	return context.WithValue(ctx, types.LoggerContextKey, logger)
 }
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// NotificationHandler receives the decoded webhook notification
+type NotificationHandler func(ctx context.Context, notification *whatsappTY.Notification) error
+
+// Decode decodes the webhook payload.
+// unknown fields are not rejected, preserved in the "Extra" field of the objects
+func Decode(payload []byte) (*whatsappTY.Notification, error) {
+	if len(payload) == 0 {
+		return nil, errors.New("empty payload")
+	}
+	notification := &whatsappTY.Notification{}
+	err := json.Unmarshal(payload, notification)
+	if err != nil {
+		return nil, fmt.Errorf("error on decoding webhook payload: %w", err)
+	}
+	return notification, nil
+}
+
+// DecodeHandler returns a payload handler, decodes the payload and calls the notification handler
+func DecodeHandler(handler NotificationHandler) PayloadHandler {
+	return func(ctx context.Context, payload []byte) error {
+		notification, err := Decode(payload)
+		if err != nil {
+			return err
+		}
+		return handler(ctx, notification)
+	}
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+// event kinds
+const (
+	EventKindMessage = "message"
+	EventKindStatus  = "status"
+	EventKindError   = "error"
+	EventKindField   = "field" // changes other than messages, example: message_template_status_update
+)
+
+// Event is a single unit of a webhook notification,
+// a notification can carry many messages and statuses
+type Event struct {
+	Kind          string
+	Field         string // field of the change
+	PhoneNumberID string // receiving phone number id, empty for non messages fields
+	BusinessID    string // whatsapp business account id
+
+	Notification *whatsappTY.Notification
+	Entry        *whatsappTY.NotificationEntry
+	Change       *whatsappTY.NotificationChange
+	Value        *whatsappTY.NotificationValue
+
+	Contact *whatsappTY.NotificationContact // sender of the message, if available
+	Message *whatsappTY.InboundMessage      // used in message kind
+	Status  *whatsappTY.MessageStatus       // used in status kind
+	Errors  []whatsappTY.NotificationError  // used in error kind
+}
+
+// Key returns an unique key of the event, used to detect duplicates.
+// returns empty, if the event has no identity
+func (e *Event) Key() string {
+	switch e.Kind {
+	case EventKindMessage:
+		if e.Message != nil && e.Message.ID != "" {
+			return e.PhoneNumberID + ":message:" + e.Message.ID
+		}
+	case EventKindStatus:
+		if e.Status != nil && e.Status.ID != "" {
+			return e.PhoneNumberID + ":status:" + e.Status.ID + ":" + e.Status.Status
+		}
+	}
+	return ""
+}
+
+// HandlerFunc handles an event
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Middleware wraps a handler
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// splits the notification into events
+func newEvents(notification *whatsappTY.Notification) []*Event {
+	events := []*Event{}
+	for entryIndex := range notification.Entry {
+		entry := &notification.Entry[entryIndex]
+		for changeIndex := range entry.Changes {
+			change := &entry.Changes[changeIndex]
+			value := change.Value
+			if value == nil {
+				value = &whatsappTY.NotificationValue{}
+			}
+			base := Event{
+				Field:        change.Field,
+				BusinessID:   entry.ID,
+				Notification: notification,
+				Entry:        entry,
+				Change:       change,
+				Value:        value,
+			}
+			if value.Metadata != nil {
+				base.PhoneNumberID = value.Metadata.PhoneNumberID
+			}
+
+			if change.Field != whatsappTY.WEBHOOK_FIELD_MESSAGES {
+				event := base
+				event.Kind = EventKindField
+				events = append(events, &event)
+				continue
+			}
+
+			for index := range value.Messages {
+				event := base
+				event.Kind = EventKindMessage
+				event.Message = &value.Messages[index]
+				event.Contact = findContact(value.Contacts, event.Message.From)
+				events = append(events, &event)
+			}
+
+			for index := range value.Statuses {
+				event := base
+				event.Kind = EventKindStatus
+				event.Status = &value.Statuses[index]
+				events = append(events, &event)
+			}
+
+			if len(value.Errors) > 0 {
+				event := base
+				event.Kind = EventKindError
+				event.Errors = value.Errors
+				events = append(events, &event)
+			}
+		}
+	}
+	return events
+}
+
+func findContact(contacts []whatsappTY.NotificationContact, waID string) *whatsappTY.NotificationContact {
+	for index := range contacts {
+		if contacts[index].WaID == waID {
+			return &contacts[index]
+		}
+	}
+	// single contact, can be used even if the wa_id is not matching (example: number changed)
+	if len(contacts) == 1 {
+		return &contacts[0]
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultMaxBodySize  = 3 * 1024 * 1024 // 3 MiB
+	DefaultReplayWindow = 10 * time.Minute
+
+	HeaderSignature = "X-Hub-Signature-256"
+	signaturePrefix = "sha256="
+
+	queryMode        = "hub.mode"
+	queryVerifyToken = "hub.verify_token"
+	queryChallenge   = "hub.challenge"
+	modeSubscribe    = "subscribe"
+)
+
+// PayloadHandler receives the verified raw webhook payload
+type PayloadHandler func(ctx context.Context, payload []byte) error
+
+// Handler is a http handler for the whatsapp webhook.
+// answers the verification handshake and validates the signature of the notifications
+// https://developers.facebook.com/docs/graph-api/webhooks/getting-started
+type Handler struct {
+	logger         *zap.Logger
+	verifyToken    string
+	appSecret      []byte
+	skipSignature  bool
+	maxBodySize    int64
+	replayDetector *replayDetector
+	handler        PayloadHandler
+}
+
+func New(ctx context.Context, cfg types.WebhookConfig, handler PayloadHandler) (*Handler, error) {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger = logger.Named("webhook_handler")
+
+	if handler == nil {
+		return nil, errors.New("payload handler can not be nil")
+	}
+	if cfg.VerifyToken == "" {
+		return nil, errors.New("verify token can not be empty")
+	}
+	if cfg.AppSecret == "" && !cfg.SkipSignatureValidation {
+		return nil, errors.New("app secret can not be empty, when signature validation enabled")
+	}
+	if cfg.SkipSignatureValidation {
+		logger.Warn("webhook signature validation is disabled")
+	}
+
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	replayWindow := cfg.ReplayWindow
+	if replayWindow == 0 {
+		replayWindow = DefaultReplayWindow
+	}
+
+	return &Handler{
+		logger:         logger,
+		verifyToken:    cfg.VerifyToken,
+		appSecret:      []byte(cfg.AppSecret),
+		skipSignature:  cfg.SkipSignatureValidation,
+		maxBodySize:    maxBodySize,
+		replayDetector: newReplayDetector(replayWindow),
+		handler:        handler,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.verify(w, r)
+	case http.MethodPost:
+		h.receive(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// answers the verification request sent by meta, when the webhook is configured
+func (h *Handler) verify(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	mode := query.Get(queryMode)
+	token := query.Get(queryVerifyToken)
+	challenge := query.Get(queryChallenge)
+
+	if mode != modeSubscribe || subtle.ConstantTimeCompare([]byte(token), []byte(h.verifyToken)) != 1 {
+		h.logger.Warn("webhook verification failed", zap.String("mode", mode), zap.String("remoteAddr", r.RemoteAddr))
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	h.logger.Info("webhook verified")
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write([]byte(challenge))
+	if err != nil {
+		h.logger.Error("error on writing challenge", zap.Error(err))
+	}
+}
+
+// receives the notification
+func (h *Handler) receive(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.logger.Warn("webhook payload too large", zap.Int64("limit", maxBytesErr.Limit))
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Error("error on reading webhook payload", zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	signature := r.Header.Get(HeaderSignature)
+	if !h.skipSignature && !h.validSignature(signature, body) {
+		h.logger.Warn("invalid webhook signature", zap.String("remoteAddr", r.RemoteAddr))
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// already processed or in progress payload, acknowledge it without processing again.
+	// keyed on the body hash, the signature is not available when the validation is skipped
+	sum := sha256.Sum256(body)
+	replayKey := hex.EncodeToString(sum[:])
+	if h.replayDetector.begin(replayKey) != replayNew {
+		h.logger.Warn("webhook payload replayed, ignored", zap.String("remoteAddr", r.RemoteAddr))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := loggerUtils.FromContext(ctx); err != nil {
+		ctx = loggerUtils.WithContext(ctx, h.logger)
+	}
+
+	err = h.handler(ctx, body)
+	if err != nil {
+		// meta retries the delivery on failure, accept the redelivery
+		h.replayDetector.remove(replayKey)
+		h.logger.Error("error on processing webhook payload", zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.replayDetector.done(replayKey)
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifies the "X-Hub-Signature-256" header, hmac sha256 of the body with app secret
+func (h *Handler) validSignature(signature string, body []byte) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.appSecret)
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
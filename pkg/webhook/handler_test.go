@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	types "github.com/jkandasa/whatsapp-cloud-api/pkg/types"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+const testAppSecret = "app-secret"
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newTestHandler(t *testing.T, cfg types.WebhookConfig, handler PayloadHandler) *Handler {
+	t.Helper()
+	if cfg.VerifyToken == "" {
+		cfg.VerifyToken = "verify-token"
+	}
+	if cfg.AppSecret == "" {
+		cfg.AppSecret = testAppSecret
+	}
+	h, err := New(loggerUtils.WithContext(context.TODO(), zap.NewNop()), cfg, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func post(h http.Handler, body, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	if signature != "" {
+		req.Header.Set(HeaderSignature, signature)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandlerVerify(t *testing.T) {
+	h := newTestHandler(t, types.WebhookConfig{}, func(ctx context.Context, payload []byte) error { return nil })
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "valid", query: "hub.mode=subscribe&hub.verify_token=verify-token&hub.challenge=1158201444", wantStatus: http.StatusOK, wantBody: "1158201444"},
+		{name: "invalid token", query: "hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=1158201444", wantStatus: http.StatusForbidden},
+		{name: "invalid mode", query: "hub.mode=unsubscribe&hub.verify_token=verify-token&hub.challenge=1158201444", wantStatus: http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook?"+tc.query, nil))
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandlerSignature(t *testing.T) {
+	body := `{"object":"whatsapp_business_account","entry":[]}`
+
+	tests := []struct {
+		name          string
+		signature     string
+		skipSignature bool
+		maxBodySize   int64
+		wantStatus    int
+		wantCalled    bool
+	}{
+		{name: "valid signature", signature: sign(testAppSecret, body), wantStatus: http.StatusOK, wantCalled: true},
+		{name: "wrong secret", signature: sign("other-secret", body), wantStatus: http.StatusUnauthorized},
+		{name: "missing signature", signature: "", wantStatus: http.StatusUnauthorized},
+		{name: "missing prefix", signature: strings.TrimPrefix(sign(testAppSecret, body), signaturePrefix), wantStatus: http.StatusUnauthorized},
+		{name: "invalid hex", signature: signaturePrefix + "zz", wantStatus: http.StatusUnauthorized},
+		{name: "validation skipped", signature: "", skipSignature: true, wantStatus: http.StatusOK, wantCalled: true},
+		{name: "body too large", signature: sign(testAppSecret, body), maxBodySize: 10, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			cfg := types.WebhookConfig{SkipSignatureValidation: tc.skipSignature, MaxBodySize: tc.maxBodySize}
+			h := newTestHandler(t, cfg, func(ctx context.Context, payload []byte) error {
+				called = true
+				if string(payload) != body {
+					t.Errorf("payload = %q, want %q", payload, body)
+				}
+				return nil
+			})
+			if status := post(h, body, tc.signature); status != tc.wantStatus {
+				t.Errorf("status = %d, want %d", status, tc.wantStatus)
+			}
+			if called != tc.wantCalled {
+				t.Errorf("handler called = %v, want %v", called, tc.wantCalled)
+			}
+		})
+	}
+}
+
+func TestHandlerReplay(t *testing.T) {
+	body := `{"object":"whatsapp_business_account","entry":[{"id":"1"}]}`
+	signature := sign(testAppSecret, body)
+
+	tests := []struct {
+		name         string
+		replayWindow time.Duration
+		results      []error // handler result per delivery
+		wantStatus   []int
+		wantCalls    int32
+	}{
+		{
+			name:       "duplicate ignored",
+			results:    []error{nil, nil},
+			wantStatus: []int{http.StatusOK, http.StatusOK},
+			wantCalls:  1,
+		},
+		{
+			name:       "redelivery after failure processed",
+			results:    []error{errors.New("failed"), nil, nil},
+			wantStatus: []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK},
+			wantCalls:  2,
+		},
+		{
+			name:         "replay detection disabled",
+			replayWindow: -1,
+			results:      []error{nil, nil},
+			wantStatus:   []int{http.StatusOK, http.StatusOK},
+			wantCalls:    2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := int32(0)
+			h := newTestHandler(t, types.WebhookConfig{ReplayWindow: tc.replayWindow}, func(ctx context.Context, payload []byte) error {
+				index := atomic.AddInt32(&calls, 1) - 1
+				return tc.results[index]
+			})
+			for index, want := range tc.wantStatus {
+				if status := post(h, body, signature); status != want {
+					t.Errorf("delivery %d: status = %d, want %d", index, status, want)
+				}
+			}
+			if got := atomic.LoadInt32(&calls); got != tc.wantCalls {
+				t.Errorf("handler calls = %d, want %d", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHandlerConcurrentDuplicates(t *testing.T) {
+	body := `{"object":"whatsapp_business_account","entry":[{"id":"2"}]}`
+	signature := sign(testAppSecret, body)
+
+	calls := int32(0)
+	release := make(chan struct{})
+	h := newTestHandler(t, types.WebhookConfig{}, func(ctx context.Context, payload []byte) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			post(h, body, signature)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("handler calls = %d, want 1", got)
+	}
+}
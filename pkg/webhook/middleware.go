@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LoggingMiddleware logs each event with the processing time and the result
+func LoggingMiddleware(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, event *Event) error {
+			startTime := time.Now()
+			err := next(ctx, event)
+
+			fields := []zap.Field{
+				zap.String("kind", event.Kind),
+				zap.String("field", event.Field),
+				zap.String("phoneNumberId", event.PhoneNumberID),
+				zap.Duration("timeTaken", time.Since(startTime)),
+			}
+			if event.Message != nil {
+				fields = append(fields, zap.String("messageId", event.Message.ID), zap.String("messageType", event.Message.Type), zap.String("from", event.Message.From))
+			}
+			if event.Status != nil {
+				fields = append(fields, zap.String("messageId", event.Status.ID), zap.String("status", event.Status.Status))
+			}
+
+			if err != nil {
+				logger.Error("error on handling webhook event", append(fields, zap.Error(err))...)
+			} else {
+				logger.Debug("webhook event handled", fields...)
+			}
+			return err
+		}
+	}
+}
+
+// RecoveryMiddleware converts the panic of a handler to an error
+func RecoveryMiddleware(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, event *Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("panic on handling webhook event", zap.Any("panic", r), zap.String("kind", event.Kind), zap.ByteString("stack", debug.Stack()))
+					err = fmt.Errorf("panic on handling webhook event: %v", r)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+// ErrEventInProgress returned by DedupMiddleware, when the same event is being handled in parallel.
+// the delivery fails and meta redelivers it, the event is not lost if the running handler fails
+var ErrEventInProgress = errors.New("event is being handled by another delivery")
+
+// DedupMiddleware skips the events already handled within the ttl.
+// meta can deliver the same event more than once.
+// the event is remembered only if the handler returns no error
+func DedupMiddleware(ttl time.Duration) Middleware {
+	detector := newReplayDetector(ttl)
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, event *Event) error {
+			key := event.Key()
+			if key == "" {
+				return next(ctx, event)
+			}
+			switch detector.begin(key) {
+			case replayDone:
+				return nil
+			case replayInProgress:
+				return ErrEventInProgress
+			}
+
+			err := next(ctx, event)
+			if err != nil {
+				detector.remove(key)
+				return err
+			}
+			detector.done(key)
+			return nil
+		}
+	}
+}
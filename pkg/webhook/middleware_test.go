@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+func TestDedupMiddleware(t *testing.T) {
+	failure := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		results   []error // handler result per delivery
+		wantErrs  []error
+		wantCalls int
+	}{
+		{
+			name:      "duplicate skipped",
+			results:   []error{nil},
+			wantErrs:  []error{nil, nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "failed event handled again",
+			results:   []error{failure, nil},
+			wantErrs:  []error{failure, nil, nil},
+			wantCalls: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			handler := DedupMiddleware(time.Minute)(func(ctx context.Context, event *Event) error {
+				calls++
+				return tc.results[calls-1]
+			})
+			for index, wantErr := range tc.wantErrs {
+				if err := handler(context.TODO(), messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, "")); !errors.Is(err, wantErr) {
+					t.Errorf("delivery %d: error = %v, want %v", index, err, wantErr)
+				}
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDedupMiddlewareInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := DedupMiddleware(time.Minute)(func(ctx context.Context, event *Event) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := handler(context.TODO(), messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, "")); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}()
+	<-started
+
+	err := handler(context.TODO(), messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""))
+	if !errors.Is(err, ErrEventInProgress) {
+		t.Errorf("error = %v, want %v", err, ErrEventInProgress)
+	}
+	close(release)
+	wg.Wait()
+}
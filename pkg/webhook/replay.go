@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// replay states of a key
+const (
+	replayNew        = iota // not seen before, marked as in progress
+	replayInProgress        // being processed by another request
+	replayDone              // processed within the window
+)
+
+type replayEntry struct {
+	done      bool
+	expiresAt time.Time
+}
+
+// keeps the processed payload keys for the given window
+type replayDetector struct {
+	mutex     sync.Mutex
+	window    time.Duration
+	entries   map[string]replayEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// negative window disables the replay detection
+func newReplayDetector(window time.Duration) *replayDetector {
+	return &replayDetector{
+		window:  window,
+		entries: map[string]replayEntry{},
+		now:     time.Now,
+	}
+}
+
+// begin returns the replay state of the key and marks a new key as in progress.
+// check and mark are done under a single lock, only one of the concurrent duplicates gets replayNew
+func (rd *replayDetector) begin(key string) int {
+	if rd.window <= 0 {
+		return replayNew
+	}
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+	now := rd.now()
+	if entry, found := rd.entries[key]; found && now.Before(entry.expiresAt) {
+		if entry.done {
+			return replayDone
+		}
+		return replayInProgress
+	}
+	// in progress entry expires too, in case done or remove never called
+	rd.entries[key] = replayEntry{expiresAt: now.Add(rd.window)}
+
+	// remove the expired entries
+	if now.Sub(rd.lastSweep) > rd.window {
+		for k, entry := range rd.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(rd.entries, k)
+			}
+		}
+		rd.lastSweep = now
+	}
+	return replayNew
+}
+
+// done marks the key as processed, the duplicates are detected for the window
+func (rd *replayDetector) done(key string) {
+	if rd.window <= 0 {
+		return
+	}
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+	rd.entries[key] = replayEntry{done: true, expiresAt: rd.now().Add(rd.window)}
+}
+
+// remove forgets the key, used when the processing failed and it has to be accepted on redelivery
+func (rd *replayDetector) remove(key string) {
+	if rd.window <= 0 {
+		return
+	}
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+	delete(rd.entries, key)
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplayDetector(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		action  string // begin, done, remove
+		want    int    // result of begin
+	}
+
+	tests := []struct {
+		name   string
+		window time.Duration
+		steps  []step
+	}{
+		{
+			name:   "done within the window",
+			window: time.Minute,
+			steps: []step{
+				{action: "begin", want: replayNew},
+				{action: "begin", want: replayInProgress},
+				{action: "done"},
+				{action: "begin", want: replayDone},
+				{advance: 59 * time.Second, action: "begin", want: replayDone},
+			},
+		},
+		{
+			name:   "expired after the window",
+			window: time.Minute,
+			steps: []step{
+				{action: "begin", want: replayNew},
+				{action: "done"},
+				{advance: time.Minute, action: "begin", want: replayNew},
+			},
+		},
+		{
+			name:   "removed on failure",
+			window: time.Minute,
+			steps: []step{
+				{action: "begin", want: replayNew},
+				{action: "remove"},
+				{action: "begin", want: replayNew},
+			},
+		},
+		{
+			name:   "stuck in progress expires",
+			window: time.Minute,
+			steps: []step{
+				{action: "begin", want: replayNew},
+				{advance: time.Minute, action: "begin", want: replayNew},
+			},
+		},
+		{
+			name:   "disabled",
+			window: -1,
+			steps: []step{
+				{action: "begin", want: replayNew},
+				{action: "done"},
+				{action: "begin", want: replayNew},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rd := newReplayDetector(tc.window)
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			rd.now = func() time.Time { return now }
+
+			for index, s := range tc.steps {
+				now = now.Add(s.advance)
+				switch s.action {
+				case "begin":
+					if got := rd.begin("key"); got != s.want {
+						t.Fatalf("step %d: begin = %d, want %d", index, got, s.want)
+					}
+				case "done":
+					rd.done("key")
+				case "remove":
+					rd.remove("key")
+				}
+			}
+		})
+	}
+}
+
+func TestReplayDetectorSweep(t *testing.T) {
+	rd := newReplayDetector(time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rd.now = func() time.Time { return now }
+
+	rd.begin("old")
+	rd.done("old")
+	now = now.Add(2 * time.Minute)
+	rd.begin("new")
+
+	if _, found := rd.entries["old"]; found {
+		t.Error("expired entry not removed")
+	}
+}
@@ -0,0 +1,314 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+	loggerUtils "github.com/jkandasa/whatsapp-cloud-api/pkg/utils/logger"
+	"go.uber.org/zap"
+)
+
+// handler specificity, the most specific handler wins across the parent and the phone number routers
+const (
+	routeNone      = iota
+	routeAnyStatus // status handler without statuses
+	routeType      // message type, status, field and error handlers
+	routeSubType   // interactive reply type handler
+)
+
+// Router dispatches the webhook events to the registered handlers.
+// handlers can be registered per receiving phone number id with PhoneNumber.
+// the most specific handler of the parent and the phone number router is used,
+// the phone number router wins on the same specificity.
+// fallback handlers are used only if none of the routers has a matching handler
+type Router struct {
+	logger              *zap.Logger
+	mutex               sync.RWMutex
+	middlewares         []Middleware
+	messageHandlers     map[string]HandlerFunc // key: message type
+	interactiveHandlers map[string]HandlerFunc // key: interactive type
+	statusHandlers      map[string]HandlerFunc // key: status, empty for any status
+	fieldHandlers       map[string]HandlerFunc // key: field
+	errorHandler        HandlerFunc
+	fallbackHandler     HandlerFunc
+	phoneNumberRouters  map[string]*Router
+}
+
+func NewRouter(ctx context.Context) *Router {
+	logger, err := loggerUtils.FromContext(ctx)
+	if err != nil {
+		logger = zap.NewNop()
+	}
+	return newRouter(logger.Named("webhook_router"))
+}
+
+func newRouter(logger *zap.Logger) *Router {
+	return &Router{
+		logger:              logger,
+		messageHandlers:     map[string]HandlerFunc{},
+		interactiveHandlers: map[string]HandlerFunc{},
+		statusHandlers:      map[string]HandlerFunc{},
+		fieldHandlers:       map[string]HandlerFunc{},
+		phoneNumberRouters:  map[string]*Router{},
+	}
+}
+
+// Use adds middlewares, the first one is the outermost
+func (r *Router) Use(middlewares ...Middleware) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// PhoneNumber returns the router for the receiving phone number id, creates if not available
+func (r *Router) PhoneNumber(phoneNumberID string) *Router {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	router, found := r.phoneNumberRouters[phoneNumberID]
+	if !found {
+		router = newRouter(r.logger.With(zap.String("phoneNumberId", phoneNumberID)))
+		r.phoneNumberRouters[phoneNumberID] = router
+	}
+	return router
+}
+
+// OnMessage registers a handler for the inbound message type
+func (r *Router) OnMessage(messageType string, handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.messageHandlers[messageType] = handler
+}
+
+func (r *Router) OnText(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_TEXT, handler)
+}
+
+func (r *Router) OnImage(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_IMAGE, handler)
+}
+
+func (r *Router) OnAudio(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_AUDIO, handler)
+}
+
+func (r *Router) OnVideo(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_VIDEO, handler)
+}
+
+func (r *Router) OnDocument(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_DOCUMENT, handler)
+}
+
+func (r *Router) OnSticker(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_STICKER, handler)
+}
+
+func (r *Router) OnLocation(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_LOCATION, handler)
+}
+
+func (r *Router) OnContacts(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_CONTACTS, handler)
+}
+
+// OnButton registers a handler for the quick reply button of a template message
+func (r *Router) OnButton(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_BUTTON, handler)
+}
+
+func (r *Router) OnReaction(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_REACTION, handler)
+}
+
+func (r *Router) OnOrder(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_ORDER, handler)
+}
+
+func (r *Router) OnSystem(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_SYSTEM, handler)
+}
+
+func (r *Router) OnUnsupported(handler HandlerFunc) {
+	r.OnMessage(whatsappTY.MESSAGE_TYPE_UNSUPPORTED, handler)
+}
+
+// OnInteractive registers a handler for the interactive reply type,
+// takes precedence over the handler registered for interactive message type
+func (r *Router) OnInteractive(interactiveType string, handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.interactiveHandlers[interactiveType] = handler
+}
+
+func (r *Router) OnInteractiveButtonReply(handler HandlerFunc) {
+	r.OnInteractive(whatsappTY.INTERACTIVE_TYPE_BUTTON_REPLY, handler)
+}
+
+func (r *Router) OnListReply(handler HandlerFunc) {
+	r.OnInteractive(whatsappTY.INTERACTIVE_TYPE_LIST_REPLY, handler)
+}
+
+func (r *Router) OnFlowCompletion(handler HandlerFunc) {
+	r.OnInteractive(whatsappTY.INTERACTIVE_TYPE_NFM_REPLY, handler)
+}
+
+// OnStatus registers a handler for the given statuses (sent, delivered, read, failed).
+// without statuses, the handler receives all the statuses not registered explicitly
+func (r *Router) OnStatus(handler HandlerFunc, statuses ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(statuses) == 0 {
+		r.statusHandlers[""] = handler
+		return
+	}
+	for _, status := range statuses {
+		r.statusHandlers[status] = handler
+	}
+}
+
+// OnField registers a handler for the webhook field other than messages
+func (r *Router) OnField(field string, handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.fieldHandlers[field] = handler
+}
+
+func (r *Router) OnTemplateStatusUpdate(handler HandlerFunc) {
+	r.OnField(whatsappTY.WEBHOOK_FIELD_MESSAGE_TEMPLATE_STATUS_UPDATE, handler)
+}
+
+// OnError registers a handler for the errors reported in the value object
+func (r *Router) OnError(handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.errorHandler = handler
+}
+
+// Fallback registers a handler for the events without a matching handler
+func (r *Router) Fallback(handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.fallbackHandler = handler
+}
+
+// HandleNotification dispatches all the events of the notification
+func (r *Router) HandleNotification(ctx context.Context, notification *whatsappTY.Notification) error {
+	errs := []error{}
+	for _, event := range newEvents(notification) {
+		err := r.Dispatch(ctx, event)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handler returns a payload handler, can be used with the webhook http handler
+func (r *Router) Handler() PayloadHandler {
+	return DecodeHandler(r.HandleNotification)
+}
+
+// Dispatch calls the matching handler of the event
+func (r *Router) Dispatch(ctx context.Context, event *Event) error {
+	handler := r.resolve(event)
+	if handler == nil {
+		r.logger.Debug("no handler found for the event", zap.String("kind", event.Kind), zap.String("field", event.Field), zap.String("phoneNumberId", event.PhoneNumberID))
+		return nil
+	}
+	return handler(ctx, event)
+}
+
+// returns the handler wrapped with middlewares, nil if no handler available
+func (r *Router) resolve(event *Event) HandlerFunc {
+	if handler, _ := r.route(event); handler != nil {
+		return handler
+	}
+	return r.fallback(event)
+}
+
+// returns the most specific handler of the router and the phone number routers, wrapped with middlewares
+func (r *Router) route(event *Event) (HandlerFunc, int) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	handler, specificity := r.lookup(event)
+	if subRouter := r.subRouter(event); subRouter != nil {
+		if subHandler, subSpecificity := subRouter.route(event); subHandler != nil && subSpecificity >= specificity {
+			handler, specificity = subHandler, subSpecificity
+		}
+	}
+	if handler == nil {
+		return nil, routeNone
+	}
+	return r.wrap(handler), specificity
+}
+
+// returns the fallback handler wrapped with middlewares, the phone number router fallback takes precedence
+func (r *Router) fallback(event *Event) HandlerFunc {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	handler := r.fallbackHandler
+	if subRouter := r.subRouter(event); subRouter != nil {
+		if subHandler := subRouter.fallback(event); subHandler != nil {
+			handler = subHandler
+		}
+	}
+	if handler == nil {
+		return nil
+	}
+	return r.wrap(handler)
+}
+
+// returns the router of the receiving phone number id, should be called with the lock held
+func (r *Router) subRouter(event *Event) *Router {
+	if event.PhoneNumberID == "" {
+		return nil
+	}
+	return r.phoneNumberRouters[event.PhoneNumberID]
+}
+
+// wraps the handler with the middlewares, should be called with the lock held
+func (r *Router) wrap(handler HandlerFunc) HandlerFunc {
+	for index := len(r.middlewares) - 1; index >= 0; index-- {
+		handler = r.middlewares[index](handler)
+	}
+	return handler
+}
+
+// returns the handler registered for the event and its specificity, without fallback and middlewares
+func (r *Router) lookup(event *Event) (HandlerFunc, int) {
+	switch event.Kind {
+	case EventKindMessage:
+		message := event.Message
+		if message.Type == whatsappTY.MESSAGE_TYPE_INTERACTIVE && message.Interactive != nil {
+			if handler, found := r.interactiveHandlers[message.Interactive.Type]; found {
+				return handler, routeSubType
+			}
+		}
+		if handler, found := r.messageHandlers[message.Type]; found {
+			return handler, routeType
+		}
+
+	case EventKindStatus:
+		if handler, found := r.statusHandlers[event.Status.Status]; found {
+			return handler, routeType
+		}
+		if handler, found := r.statusHandlers[""]; found {
+			return handler, routeAnyStatus
+		}
+
+	case EventKindError:
+		if r.errorHandler != nil {
+			return r.errorHandler, routeType
+		}
+
+	case EventKindField:
+		if handler, found := r.fieldHandlers[event.Field]; found {
+			return handler, routeType
+		}
+	}
+	return nil, routeNone
+}
@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	whatsappTY "github.com/jkandasa/whatsapp-cloud-api/pkg/types/whatsapp"
+)
+
+const testPhoneNumberID = "phone-1"
+
+func messageEvent(messageType, interactiveType string) *Event {
+	message := &whatsappTY.InboundMessage{ID: "wamid.1", From: "919876543210", Type: messageType}
+	if interactiveType != "" {
+		message.Interactive = &whatsappTY.InboundInteractiveObject{Type: interactiveType}
+	}
+	return &Event{Kind: EventKindMessage, Field: whatsappTY.WEBHOOK_FIELD_MESSAGES, PhoneNumberID: testPhoneNumberID, Message: message}
+}
+
+func statusEvent(status string) *Event {
+	return &Event{Kind: EventKindStatus, Field: whatsappTY.WEBHOOK_FIELD_MESSAGES, PhoneNumberID: testPhoneNumberID, Status: &whatsappTY.MessageStatus{ID: "wamid.1", Status: status}}
+}
+
+func TestRouterResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Router, h func(name string) HandlerFunc)
+		event *Event
+		want  string // name of the called handler, empty if none
+	}{
+		{
+			name: "message type",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnText(h("text"))
+				r.OnImage(h("image"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "text",
+		},
+		{
+			name: "interactive type over message type",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnMessage(whatsappTY.MESSAGE_TYPE_INTERACTIVE, h("interactive"))
+				r.OnListReply(h("list_reply"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_INTERACTIVE, whatsappTY.INTERACTIVE_TYPE_LIST_REPLY),
+			want:  "list_reply",
+		},
+		{
+			name: "interactive falls back to message type",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnMessage(whatsappTY.MESSAGE_TYPE_INTERACTIVE, h("interactive"))
+				r.OnListReply(h("list_reply"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_INTERACTIVE, whatsappTY.INTERACTIVE_TYPE_BUTTON_REPLY),
+			want:  "interactive",
+		},
+		{
+			name: "specific status over any status",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnStatus(h("any"))
+				r.OnStatus(h("failed"), "failed")
+			},
+			event: statusEvent("failed"),
+			want:  "failed",
+		},
+		{
+			name: "any status",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnStatus(h("any"))
+				r.OnStatus(h("failed"), "failed")
+			},
+			event: statusEvent("read"),
+			want:  "any",
+		},
+		{
+			name: "field",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnTemplateStatusUpdate(h("template_status"))
+			},
+			event: &Event{Kind: EventKindField, Field: whatsappTY.WEBHOOK_FIELD_MESSAGE_TEMPLATE_STATUS_UPDATE},
+			want:  "template_status",
+		},
+		{
+			name: "error",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnError(h("error"))
+			},
+			event: &Event{Kind: EventKindError, PhoneNumberID: testPhoneNumberID},
+			want:  "error",
+		},
+		{
+			name: "phone number router wins on same specificity",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnText(h("parent_text"))
+				r.PhoneNumber(testPhoneNumberID).OnText(h("phone_text"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "phone_text",
+		},
+		{
+			name: "parent specific handler over phone number fallback",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnText(h("parent_text"))
+				r.PhoneNumber(testPhoneNumberID).Fallback(h("phone_fallback"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "parent_text",
+		},
+		{
+			name: "parent specific status over phone number any status",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnStatus(h("parent_failed"), "failed")
+				r.PhoneNumber(testPhoneNumberID).OnStatus(h("phone_any"))
+			},
+			event: statusEvent("failed"),
+			want:  "parent_failed",
+		},
+		{
+			name: "parent interactive type over phone number message type",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnListReply(h("parent_list_reply"))
+				r.PhoneNumber(testPhoneNumberID).OnMessage(whatsappTY.MESSAGE_TYPE_INTERACTIVE, h("phone_interactive"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_INTERACTIVE, whatsappTY.INTERACTIVE_TYPE_LIST_REPLY),
+			want:  "parent_list_reply",
+		},
+		{
+			name: "other phone number router ignored",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnText(h("parent_text"))
+				r.PhoneNumber("phone-2").OnText(h("other_phone_text"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "parent_text",
+		},
+		{
+			name: "phone number fallback over parent fallback",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.Fallback(h("parent_fallback"))
+				r.PhoneNumber(testPhoneNumberID).Fallback(h("phone_fallback"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "phone_fallback",
+		},
+		{
+			name: "parent fallback",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.Fallback(h("parent_fallback"))
+				r.PhoneNumber(testPhoneNumberID).OnImage(h("phone_image"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "parent_fallback",
+		},
+		{
+			name: "no handler",
+			setup: func(r *Router, h func(string) HandlerFunc) {
+				r.OnImage(h("image"))
+			},
+			event: messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, ""),
+			want:  "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := ""
+			handler := func(name string) HandlerFunc {
+				return func(ctx context.Context, event *Event) error {
+					called = name
+					return nil
+				}
+			}
+			router := NewRouter(context.TODO())
+			tc.setup(router, handler)
+
+			if err := router.Dispatch(context.TODO(), tc.event); err != nil {
+				t.Fatal(err)
+			}
+			if called != tc.want {
+				t.Errorf("called = %q, want %q", called, tc.want)
+			}
+		})
+	}
+}
+
+func TestRouterMiddlewareOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Router, h HandlerFunc)
+		want  []string
+	}{
+		{
+			name: "parent handler",
+			setup: func(r *Router, h HandlerFunc) {
+				r.OnText(h)
+			},
+			want: []string{"parent_1", "parent_2", "handler"},
+		},
+		{
+			name: "phone number handler",
+			setup: func(r *Router, h HandlerFunc) {
+				r.PhoneNumber(testPhoneNumberID).OnText(h)
+			},
+			want: []string{"parent_1", "parent_2", "phone", "handler"},
+		},
+		{
+			name: "phone number fallback",
+			setup: func(r *Router, h HandlerFunc) {
+				r.PhoneNumber(testPhoneNumberID).Fallback(h)
+			},
+			want: []string{"parent_1", "parent_2", "phone", "handler"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := []string{}
+			middleware := func(name string) Middleware {
+				return func(next HandlerFunc) HandlerFunc {
+					return func(ctx context.Context, event *Event) error {
+						calls = append(calls, name)
+						return next(ctx, event)
+					}
+				}
+			}
+			router := NewRouter(context.TODO())
+			router.Use(middleware("parent_1"), middleware("parent_2"))
+			router.PhoneNumber(testPhoneNumberID).Use(middleware("phone"))
+			tc.setup(router, func(ctx context.Context, event *Event) error {
+				calls = append(calls, "handler")
+				return nil
+			})
+
+			if err := router.Dispatch(context.TODO(), messageEvent(whatsappTY.MESSAGE_TYPE_TEXT, "")); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(calls, tc.want) {
+				t.Errorf("calls = %v, want %v", calls, tc.want)
+			}
+		})
+	}
+}